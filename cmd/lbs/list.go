@@ -18,6 +18,8 @@ type PackageInfo struct {
 	Name                        string    `json:"Name"`
 	Version                     string    `json:"Version"`
 	Description                 string    `json:"Description"`
+	Platform                    string    `json:"Platform"`
+	Arch                        string    `json:"Arch"`
 	FilePath                    string    `json:"FilePath"`
 	FileHash                    string    `json:"FileHash"`
 	FileSize                    int64     `json:"FileSize"`
@@ -26,6 +28,9 @@ type PackageInfo struct {
 	MaintainerFingerprint       string    `json:"MaintainerFingerprint"`
 	ManifestSignature           string    `json:"ManifestSignature"`
 	MaintainerManifestSignature string    `json:"MaintainerManifestSignature"`
+	State                       string    `json:"State"`
+	Verified                    bool      `json:"Verified"`
+	LastVerifiedAt              time.Time `json:"LastVerifiedAt"`
 	AnnouncedToDHT              bool      `json:"AnnouncedToDHT"`
 	LastAnnounced               time.Time `json:"LastAnnounced"`
 }
@@ -134,8 +139,22 @@ func listCommand(args []string) error {
 			fmt.Printf("    Maintainer:  %s\n", pkg.MaintainerFingerprint)
 		}
 
+		if pkg.Platform != "" || pkg.Arch != "" {
+			fmt.Printf("    Target:      %s/%s\n", orAny(pkg.Platform), orAny(pkg.Arch))
+		}
+
 		fmt.Printf("    Created At:  %s\n", pkg.CreatedAt.Format("2006-01-02 15:04:05 MST"))
 
+		if pkg.State != "" {
+			fmt.Printf("    State:       %s\n", pkg.State)
+		}
+
+		if pkg.Verified {
+			fmt.Printf("    Verified:    Yes (Last: %s)\n", pkg.LastVerifiedAt.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("    Verified:    No\n")
+		}
+
 		if pkg.AnnouncedToDHT {
 			fmt.Printf("    DHT Status:  Announced (Last: %s)\n", pkg.LastAnnounced.Format("2006-01-02 15:04:05"))
 		} else {
@@ -145,3 +164,11 @@ func listCommand(args []string) error {
 
 	return nil
 }
+
+// orAny returns s, or "any" when s is empty.
+func orAny(s string) string {
+	if s == "" {
+		return "any"
+	}
+	return s
+}
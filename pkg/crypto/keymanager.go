@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // KeyManager handles Ed25519 keypair generation, storage, and loading.
@@ -246,6 +247,49 @@ func (km *KeyManager) Fingerprint() string {
 	return pubKey.Fingerprint()
 }
 
+// SignDomain signs data with the loaded private key under a domain
+// separation tag, so a signature made for one purpose (e.g. a receipt) can
+// never be replayed as a signature for another (e.g. an index). The signed
+// message is the domain, a newline, then data; verify with VerifyDomain.
+//
+// Returns the raw 64-byte signature, or error if keys haven't been loaded
+// or the domain is empty or contains a newline.
+func (km *KeyManager) SignDomain(domain string, data []byte) ([]byte, error) {
+	if km.privateKey == nil {
+		return nil, fmt.Errorf("keys not loaded")
+	}
+	message, err := domainMessage(domain, data)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(km.privateKey, message), nil
+}
+
+// VerifyDomain checks a signature made by KeyManager.SignDomain.
+// Returns false if the key is nil, the domain is invalid, or the signature
+// does not match.
+func VerifyDomain(publicKey *PublicKey, domain string, data, signature []byte) bool {
+	if publicKey == nil {
+		return false
+	}
+	message, err := domainMessage(domain, data)
+	if err != nil {
+		return false
+	}
+	return publicKey.Verify(message, signature)
+}
+
+// domainMessage builds the message signed under a domain separation tag.
+func domainMessage(domain string, data []byte) ([]byte, error) {
+	if domain == "" || strings.Contains(domain, "\n") {
+		return nil, fmt.Errorf("invalid signing domain %q", domain)
+	}
+	message := make([]byte, 0, len(domain)+1+len(data))
+	message = append(message, domain...)
+	message = append(message, '\n')
+	return append(message, data...), nil
+}
+
 // KeysDir returns the directory where keys are stored.
 func (km *KeyManager) KeysDir() string {
 	return km.keysDir
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/libreseed/libreseed/pkg/dht"
 )
 
 // DaemonConfig holds the configuration for the libreseed daemon.
@@ -21,6 +24,11 @@ type DaemonConfig struct {
 	// DHTPort is the UDP port for DHT operations (default: 6881)
 	DHTPort int `yaml:"dht_port"`
 
+	// DHTAnnouncePort is the port advertised to peers in DHT announces.
+	// Set this when the daemon sits behind NAT/port-forwarding and peers must
+	// dial a different port than the one we listen on (0 = same as DHTPort)
+	DHTAnnouncePort int `yaml:"dht_announce_port"`
+
 	// DHTBootstrapNodes is the list of DHT bootstrap nodes
 	DHTBootstrapNodes []string `yaml:"dht_bootstrap_nodes"`
 
@@ -39,13 +47,59 @@ type DaemonConfig struct {
 	// EnablePEX enables or disables Peer Exchange
 	EnablePEX bool `yaml:"enable_pex"`
 
+	// DHTBootstrapTimeout is how long /ready waits for the DHT to find its
+	// first node before reporting ready as degraded (0 = default of 2 minutes)
+	DHTBootstrapTimeout time.Duration `yaml:"dht_bootstrap_timeout"`
+
 	// AnnounceInterval is how often to announce to trackers
 	AnnounceInterval time.Duration `yaml:"announce_interval"`
 
+	// AnnounceJitter is the random delay window added to each package's
+	// republish time so packages don't re-announce in lockstep
+	// (default: 5 minutes, 0 = none)
+	AnnounceJitter time.Duration `yaml:"announce_jitter"`
+
+	// AnnounceBreakerThreshold is the number of consecutive DHT announce
+	// failures that opens the announce circuit breaker (default: 5, 0 = disabled)
+	AnnounceBreakerThreshold int `yaml:"announce_breaker_threshold"`
+
+	// AnnounceBreakerCooldown is how long the breaker stays open before
+	// probing the DHT again (0 = default of 5 minutes)
+	AnnounceBreakerCooldown time.Duration `yaml:"announce_breaker_cooldown"`
+
+	// AnnounceByContentHash derives each package's DHT InfoHash from its
+	// manifest content hash instead of its package ID, so packages with
+	// identical content share a single DHT entry. Peers looking a package up
+	// by its package ID no longer find this seeder. Requires
+	// VerifyContentHash, since the declared hash becomes the DHT key
+	AnnounceByContentHash bool `yaml:"announce_by_content_hash"`
+
+	// DiscoveryCacheGrace is how long expired discovery results are still
+	// served (flagged stale) to ride out DHT churn (0 = drop at TTL)
+	DiscoveryCacheGrace time.Duration `yaml:"discovery_cache_grace"`
+
+	// AllowedExtensions lists the file extensions accepted for uploaded
+	// packages, compared case-insensitively (empty = only ".lspkg")
+	AllowedExtensions []string `yaml:"allowed_extensions"`
+
+	// VerifyContentHash rejects packages whose manifest content_hash does not
+	// match the canonical hash of their content list (see
+	// packagetypes.ComputeManifestContentHash). Off by default: packages built
+	// with another content_hash scheme would all be rejected
+	VerifyContentHash bool `yaml:"verify_content_hash"`
+
+	// MaxVersionsPerPackage is how many versions of a package name are kept;
+	// older versions are evicted when a newer one is added (0 = unlimited)
+	MaxVersionsPerPackage int `yaml:"max_versions_per_package"`
+
 	// LogLevel is the logging verbosity (debug, info, warn, error)
 	LogLevel string `yaml:"log_level"`
 }
 
+// defaultAllowedExtensions are the package file extensions accepted when
+// AllowedExtensions is not configured.
+var defaultAllowedExtensions = []string{".lspkg"}
+
 // DefaultConfig returns a DaemonConfig with sensible defaults.
 func DefaultConfig() *DaemonConfig {
 	homeDir, err := os.UserHomeDir()
@@ -62,13 +116,20 @@ func DefaultConfig() *DaemonConfig {
 			"dht.transmissionbt.com:2710",
 			"router.utorrent.com:6881",
 		},
-		MaxUploadRate:    0, // unlimited
-		MaxDownloadRate:  0, // unlimited
-		MaxConnections:   100,
-		EnableDHT:        true,
-		EnablePEX:        true,
-		AnnounceInterval: 30 * time.Minute,
-		LogLevel:         "info",
+		MaxUploadRate:            0, // unlimited
+		MaxDownloadRate:          0, // unlimited
+		MaxConnections:           100,
+		EnableDHT:                true,
+		EnablePEX:                true,
+		AnnounceInterval:         30 * time.Minute,
+		AnnounceJitter:           5 * time.Minute,
+		AnnounceBreakerThreshold: dht.DefaultBreakerThreshold,
+		AnnounceBreakerCooldown:  dht.DefaultBreakerCooldown,
+		DiscoveryCacheGrace:      5 * time.Minute,
+		AllowedExtensions:        slices.Clone(defaultAllowedExtensions),
+		VerifyContentHash:        false, // opt-in; no packaging tool emits the canonical hash yet
+		MaxVersionsPerPackage:    0,     // unlimited
+		LogLevel:                 "info",
 	}
 }
 
@@ -80,13 +141,23 @@ func DefaultConfig() *DaemonConfig {
 //   - LIBRESEED_LISTEN_ADDR: HTTP server address
 //   - LIBRESEED_STORAGE_DIR: Storage directory path
 //   - LIBRESEED_DHT_PORT: DHT UDP port
+//   - LIBRESEED_DHT_ANNOUNCE_PORT: Port advertised in DHT announces
 //   - LIBRESEED_DHT_BOOTSTRAP_NODES: Comma-separated list of bootstrap nodes
 //   - LIBRESEED_MAX_UPLOAD_RATE: Maximum upload rate in bytes/sec
 //   - LIBRESEED_MAX_DOWNLOAD_RATE: Maximum download rate in bytes/sec
 //   - LIBRESEED_MAX_CONNECTIONS: Maximum peer connections
 //   - LIBRESEED_ENABLE_DHT: Enable DHT (true/false)
 //   - LIBRESEED_ENABLE_PEX: Enable PEX (true/false)
+//   - LIBRESEED_DHT_BOOTSTRAP_TIMEOUT: Readiness wait for DHT bootstrap (e.g., "2m")
 //   - LIBRESEED_ANNOUNCE_INTERVAL: Announce interval (e.g., "30m", "1h")
+//   - LIBRESEED_ANNOUNCE_JITTER: Republish jitter window (e.g., "5m")
+//   - LIBRESEED_ANNOUNCE_BREAKER_THRESHOLD: Consecutive announce failures before backing off (0 = disabled)
+//   - LIBRESEED_ANNOUNCE_BREAKER_COOLDOWN: Announce backoff duration (e.g., "5m")
+//   - LIBRESEED_ANNOUNCE_BY_CONTENT_HASH: Share one DHT entry per identical content (true/false)
+//   - LIBRESEED_DISCOVERY_CACHE_GRACE: Stale discovery grace window (e.g., "5m")
+//   - LIBRESEED_ALLOWED_EXTENSIONS: Comma-separated list of accepted package file extensions
+//   - LIBRESEED_VERIFY_CONTENT_HASH: Verify manifest content hash on add (true/false)
+//   - LIBRESEED_MAX_VERSIONS_PER_PACKAGE: Versions kept per package name (0 = unlimited)
 //   - LIBRESEED_LOG_LEVEL: Log level (debug/info/warn/error)
 func (c *DaemonConfig) LoadFromEnv() error {
 	if val := os.Getenv("LIBRESEED_LISTEN_ADDR"); val != "" {
@@ -105,6 +176,14 @@ func (c *DaemonConfig) LoadFromEnv() error {
 		c.DHTPort = port
 	}
 
+	if val := os.Getenv("LIBRESEED_DHT_ANNOUNCE_PORT"); val != "" {
+		port, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_DHT_ANNOUNCE_PORT: %w", err)
+		}
+		c.DHTAnnouncePort = port
+	}
+
 	if val := os.Getenv("LIBRESEED_DHT_BOOTSTRAP_NODES"); val != "" {
 		nodes := strings.Split(val, ",")
 		// Trim whitespace from each node
@@ -154,6 +233,14 @@ func (c *DaemonConfig) LoadFromEnv() error {
 		c.EnablePEX = enabled
 	}
 
+	if val := os.Getenv("LIBRESEED_DHT_BOOTSTRAP_TIMEOUT"); val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_DHT_BOOTSTRAP_TIMEOUT: %w", err)
+		}
+		c.DHTBootstrapTimeout = timeout
+	}
+
 	if val := os.Getenv("LIBRESEED_ANNOUNCE_INTERVAL"); val != "" {
 		interval, err := time.ParseDuration(val)
 		if err != nil {
@@ -162,6 +249,71 @@ func (c *DaemonConfig) LoadFromEnv() error {
 		c.AnnounceInterval = interval
 	}
 
+	if val := os.Getenv("LIBRESEED_ANNOUNCE_JITTER"); val != "" {
+		jitter, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_ANNOUNCE_JITTER: %w", err)
+		}
+		c.AnnounceJitter = jitter
+	}
+
+	if val := os.Getenv("LIBRESEED_ANNOUNCE_BREAKER_THRESHOLD"); val != "" {
+		threshold, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_ANNOUNCE_BREAKER_THRESHOLD: %w", err)
+		}
+		c.AnnounceBreakerThreshold = threshold
+	}
+
+	if val := os.Getenv("LIBRESEED_ANNOUNCE_BREAKER_COOLDOWN"); val != "" {
+		cooldown, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_ANNOUNCE_BREAKER_COOLDOWN: %w", err)
+		}
+		c.AnnounceBreakerCooldown = cooldown
+	}
+
+	if val := os.Getenv("LIBRESEED_ANNOUNCE_BY_CONTENT_HASH"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_ANNOUNCE_BY_CONTENT_HASH: %w", err)
+		}
+		c.AnnounceByContentHash = enabled
+	}
+
+	if val := os.Getenv("LIBRESEED_DISCOVERY_CACHE_GRACE"); val != "" {
+		grace, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_DISCOVERY_CACHE_GRACE: %w", err)
+		}
+		c.DiscoveryCacheGrace = grace
+	}
+
+	if val := os.Getenv("LIBRESEED_ALLOWED_EXTENSIONS"); val != "" {
+		exts := strings.Split(val, ",")
+		// Trim whitespace from each extension
+		for i := range exts {
+			exts[i] = strings.TrimSpace(exts[i])
+		}
+		c.AllowedExtensions = exts
+	}
+
+	if val := os.Getenv("LIBRESEED_VERIFY_CONTENT_HASH"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_VERIFY_CONTENT_HASH: %w", err)
+		}
+		c.VerifyContentHash = enabled
+	}
+
+	if val := os.Getenv("LIBRESEED_MAX_VERSIONS_PER_PACKAGE"); val != "" {
+		maxVersions, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid LIBRESEED_MAX_VERSIONS_PER_PACKAGE: %w", err)
+		}
+		c.MaxVersionsPerPackage = maxVersions
+	}
+
 	if val := os.Getenv("LIBRESEED_LOG_LEVEL"); val != "" {
 		c.LogLevel = strings.ToLower(val)
 	}
@@ -183,6 +335,10 @@ func (c *DaemonConfig) Validate() error {
 		return fmt.Errorf("dht_port must be between 1024 and 65535")
 	}
 
+	if c.DHTAnnouncePort < 0 || c.DHTAnnouncePort > 65535 {
+		return fmt.Errorf("dht_announce_port must be between 1 and 65535 (or 0 to use dht_port)")
+	}
+
 	if c.EnableDHT && len(c.DHTBootstrapNodes) == 0 {
 		return fmt.Errorf("dht_bootstrap_nodes cannot be empty when DHT is enabled")
 	}
@@ -199,10 +355,44 @@ func (c *DaemonConfig) Validate() error {
 		return fmt.Errorf("max_connections must be at least 1")
 	}
 
+	if c.DHTBootstrapTimeout < 0 {
+		return fmt.Errorf("dht_bootstrap_timeout cannot be negative")
+	}
+
 	if c.AnnounceInterval < time.Minute {
 		return fmt.Errorf("announce_interval must be at least 1 minute")
 	}
 
+	if c.AnnounceJitter < 0 {
+		return fmt.Errorf("announce_jitter cannot be negative")
+	}
+
+	if c.AnnounceBreakerThreshold < 0 {
+		return fmt.Errorf("announce_breaker_threshold cannot be negative")
+	}
+
+	if c.AnnounceBreakerCooldown < 0 {
+		return fmt.Errorf("announce_breaker_cooldown cannot be negative")
+	}
+
+	if c.AnnounceByContentHash && !c.VerifyContentHash {
+		return fmt.Errorf("announce_by_content_hash requires verify_content_hash")
+	}
+
+	if c.DiscoveryCacheGrace < 0 {
+		return fmt.Errorf("discovery_cache_grace cannot be negative")
+	}
+
+	for _, ext := range c.AllowedExtensions {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+			return fmt.Errorf("allowed_extensions entries must start with '.' (got %q)", ext)
+		}
+	}
+
+	if c.MaxVersionsPerPackage < 0 {
+		return fmt.Errorf("max_versions_per_package cannot be negative")
+	}
+
 	validLogLevels := map[string]bool{
 		"debug": true,
 		"info":  true,
@@ -220,3 +410,20 @@ func (c *DaemonConfig) Validate() error {
 func (c *DaemonConfig) EnsureStorageDir() error {
 	return os.MkdirAll(c.StorageDir, 0755)
 }
+
+// IsAllowedExtension reports whether a package filename has an accepted extension.
+// When AllowedExtensions is empty the default extensions (".lspkg") are accepted.
+func (c *DaemonConfig) IsAllowedExtension(filename string) bool {
+	allowed := c.AllowedExtensions
+	if len(allowed) == 0 {
+		allowed = defaultAllowedExtensions
+	}
+
+	ext := filepath.Ext(filename)
+	for _, candidate := range allowed {
+		if strings.EqualFold(ext, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -13,7 +13,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/anacrolix/torrent/metainfo"
 	"github.com/libreseed/libreseed/pkg/crypto"
 	"github.com/libreseed/libreseed/pkg/dht"
 	"github.com/libreseed/libreseed/pkg/storage"
@@ -30,6 +29,7 @@ type Daemon struct {
 
 	// DHT components
 	dhtClient   *dht.Client
+	bootstrap   *dht.BootstrapWatcher
 	announcer   *dht.Announcer
 	discovery   *dht.Discovery
 	peerManager *dht.PeerManager
@@ -90,23 +90,24 @@ func New(config *DaemonConfig) (*Daemon, error) {
 
 	// Initialize PackageManager
 	packageManager := NewPackageManager(packagesDir, metaFile)
+	packageManager.SetAcceptanceRules(config)
 	if err := packageManager.LoadState(); err != nil {
 		return nil, fmt.Errorf("failed to load package state: %w", err)
 	}
 	d.packageManager = packageManager
 
 	// Initialize DHT components
-	dhtConfig := &dht.ClientConfig{
-		Port:           config.DHTPort,
-		BootstrapNodes: config.DHTBootstrapNodes,
-	}
-	dhtClient, err := dht.NewClient(dhtConfig)
+	dhtClient, err := dht.NewClient(newDHTClientConfig(config))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DHT client: %w", err)
 	}
 	d.dhtClient = dhtClient
 	d.announcer = dht.NewAnnouncer(dhtClient, 30*time.Minute)
+	d.announcer.SetAnnouncePort(dhtClient.AnnouncePort())
+	d.announcer.SetJitter(config.AnnounceJitter)
+	d.announcer.SetCircuitBreaker(config.AnnounceBreakerThreshold, config.AnnounceBreakerCooldown)
 	d.discovery = dht.NewDiscovery(dhtClient, 15*time.Minute)
+	d.discovery.SetGracePeriod(config.DiscoveryCacheGrace)
 	d.peerManager = dht.NewPeerManager()
 
 	// Setup HTTP server
@@ -124,6 +125,21 @@ func New(config *DaemonConfig) (*Daemon, error) {
 	return d, nil
 }
 
+// newDHTClientConfig builds the DHT client configuration from the daemon configuration.
+// The announce port defaults to the DHT listen port unless explicitly overridden.
+func newDHTClientConfig(config *DaemonConfig) *dht.ClientConfig {
+	announcePort := config.DHTAnnouncePort
+	if announcePort == 0 {
+		announcePort = config.DHTPort
+	}
+
+	return &dht.ClientConfig{
+		Port:           config.DHTPort,
+		AnnouncePort:   announcePort,
+		BootstrapNodes: config.DHTBootstrapNodes,
+	}
+}
+
 // Start starts the daemon and begins serving requests.
 func (d *Daemon) Start() error {
 	d.mu.Lock()
@@ -153,33 +169,14 @@ func (d *Daemon) Start() error {
 			return fmt.Errorf("failed to start DHT client: %w", err)
 		}
 
+		// Track bootstrap progress for the readiness check
+		d.bootstrap = dht.NewBootstrapWatcher(d.dhtClient, d.config.DHTBootstrapTimeout)
+
 		// Start announcer
 		d.announcer.Start()
 
 		// Populate announcer with existing packages from database
-		log.Println("=== Populating announcer with existing packages ===")
-		existingPackages := d.packageManager.ListPackages()
-		log.Printf("Found %d packages in database to sync to announcer", len(existingPackages))
-		for _, pkg := range existingPackages {
-			log.Printf("Adding package to announcer: %s (%s)", pkg.Name, pkg.PackageID)
-
-			// Convert package ID (hex string) to InfoHash
-			infoHashBytes, err := hex.DecodeString(pkg.PackageID)
-			if err != nil {
-				log.Printf("Warning: Failed to decode package ID %s: %v", pkg.PackageID, err)
-				continue
-			}
-			if len(infoHashBytes) < 20 {
-				log.Printf("Warning: Package ID %s too short (need 20 bytes, got %d)", pkg.PackageID, len(infoHashBytes))
-				continue
-			}
-
-			var infoHash metainfo.Hash
-			copy(infoHash[:], infoHashBytes[:20])
-			// Use package fingerprints for DHT announcement
-			d.announcer.AddPackage(infoHash, pkg.Name, pkg.CreatorFingerprint, pkg.MaintainerFingerprint)
-		}
-		log.Println("=== Announcer population complete ===")
+		d.syncAnnouncer()
 	}
 
 	// Start HTTP server in background
@@ -196,6 +193,33 @@ func (d *Daemon) Start() error {
 	return nil
 }
 
+// syncAnnouncer adds every released package from the database to the announcer.
+// Pending and yanked packages are not announced.
+func (d *Daemon) syncAnnouncer() {
+	log.Println("=== Populating announcer with existing packages ===")
+	existingPackages := d.packageManager.ListPackages()
+	log.Printf("Found %d packages in database to sync to announcer", len(existingPackages))
+	for _, pkg := range existingPackages {
+		if pkg.State != StateReleased {
+			log.Printf("Skipping %s package: %s (%s)", pkg.State, pkg.Name, pkg.PackageID)
+			continue
+		}
+
+		log.Printf("Adding package to announcer: %s (%s)", pkg.Name, pkg.PackageID)
+
+		infoHash, err := d.announceInfoHash(pkg)
+		if err != nil {
+			log.Printf("Warning: Failed to convert package ID %s to InfoHash: %v", pkg.PackageID, err)
+			continue
+		}
+
+		// Use package fingerprints for DHT announcement; packages sharing
+		// an InfoHash are announced once
+		d.announcer.AddPackageRef(infoHash, pkg.PackageID, pkg.Name, pkg.CreatorFingerprint, pkg.MaintainerFingerprint)
+	}
+	log.Println("=== Announcer population complete ===")
+}
+
 // Stop gracefully stops the daemon.
 func (d *Daemon) Stop() error {
 	d.mu.Lock()
@@ -279,6 +303,11 @@ func (d *Daemon) backgroundWorker() {
 
 // performPeriodicTasks executes periodic maintenance and updates.
 func (d *Daemon) performPeriodicTasks() {
+	// Drop the verified status of package files changed on disk
+	if err := d.packageManager.InvalidateChanged(); err != nil {
+		log.Printf("Warning: Failed to update verification status: %v", err)
+	}
+
 	if !d.config.EnableDHT {
 		return
 	}
@@ -300,18 +329,56 @@ func (d *Daemon) performPeriodicTasks() {
 
 	// Clear expired entries from discovery cache
 	d.discovery.ClearExpired()
+
+	// Reflect failed and deferred announces in the package database
+	d.syncAnnouncementStatus()
+}
+
+// syncAnnouncementStatus copies the announcer's outcome for each package into
+// the package database. Packages whose last announce failed, or was deferred
+// by the open circuit breaker, are marked as not announced until an announce
+// succeeds again.
+func (d *Daemon) syncAnnouncementStatus() {
+	lastAnnounced := make(map[string]time.Time)
+	for _, announcement := range d.announcer.GetPackages() {
+		if announcement.LastAnnounced.IsZero() && !announcement.Failed {
+			// Not attempted yet
+			continue
+		}
+		at := announcement.LastAnnounced
+		if announcement.Failed {
+			at = time.Time{}
+		}
+		for _, packageID := range announcement.PackageIDs {
+			lastAnnounced[packageID] = at
+		}
+	}
+
+	if err := d.packageManager.SyncAnnouncementStatus(lastAnnounced); err != nil {
+		log.Printf("Warning: Failed to update announcement status: %v", err)
+	}
 }
 
 // registerRoutes sets up HTTP API routes.
 func (d *Daemon) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", d.handleHealth)
+	mux.HandleFunc("/ready", d.handleReady)
 	mux.HandleFunc("/status", d.handleStatus)
 	mux.HandleFunc("/stats", d.handleStats)
 	mux.HandleFunc("/shutdown", d.handleShutdown)
+	mux.HandleFunc("/identity", d.handleIdentity)
+	mux.HandleFunc("GET /index.json", d.handleIndex)
 
 	// Package management endpoints
 	mux.HandleFunc("POST /packages/add", d.handlePackageAdd)
 	mux.HandleFunc("GET /packages/list", d.handlePackageList)
+	mux.HandleFunc("GET /packages/recent", d.handlePackageRecent)
+	mux.HandleFunc("POST /packages/scrub", d.handlePackageScrub)
+	mux.HandleFunc("POST /packages/{id}/touch", d.handlePackageTouch)
+	mux.HandleFunc("POST /packages/{id}/promote", d.handlePackagePromote)
+	mux.HandleFunc("POST /packages/{id}/yank", d.handlePackageYank)
+	mux.HandleFunc("POST /packages/{id}/verify", d.handlePackageVerify)
+	mux.HandleFunc("GET /packages/{id}/dependencies/resolve", d.handlePackageDependencies)
 	mux.HandleFunc("DELETE /packages/remove", d.handlePackageRemove)
 
 	// DHT-specific endpoints (only if DHT is enabled)
@@ -336,6 +403,81 @@ func (d *Daemon) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleReady reports whether the daemon is ready to serve traffic.
+// GET /ready
+//
+// The daemon is ready once it is running and, when DHT is enabled, the DHT
+// has bootstrapped at least one node. Until then it reports status
+// "waiting-for-dht"; if bootstrap does not complete within DHTBootstrapTimeout
+// (default 2 minutes), it reports ready with status "degraded-dht".
+// Not ready is reported with 503.
+func (d *Daemon) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ready := d.state.GetStatus() == StatusRunning
+	status := "ready"
+	if !ready {
+		status = "starting"
+	}
+
+	response := map[string]interface{}{}
+
+	if d.config.EnableDHT && ready {
+		if d.bootstrap == nil {
+			ready, status = false, "starting"
+		} else {
+			bootstrap := d.bootstrap.Status()
+			switch bootstrap.State {
+			case dht.BootstrapPending:
+				ready, status = false, "waiting-for-dht"
+			case dht.BootstrapTimedOut:
+				status = "degraded-dht"
+			}
+
+			response["dht_bootstrap"] = map[string]interface{}{
+				"state":   string(bootstrap.State),
+				"nodes":   bootstrap.Nodes,
+				"timeout": bootstrap.Timeout.String(),
+			}
+		}
+	}
+
+	response["ready"] = ready
+	response["status"] = status
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleIdentity returns the daemon's public identity.
+// GET /identity
+func (d *Daemon) handleIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if d.keyManager == nil || d.keyManager.PublicKey() == nil {
+		http.Error(w, "Daemon keys not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	response := map[string]interface{}{
+		"seeder_id":  d.keyManager.Fingerprint(),
+		"algorithm":  "ed25519",
+		"public_key": hex.EncodeToString(d.keyManager.PublicKey()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // handleStatus returns the current daemon state.
 func (d *Daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -359,6 +501,20 @@ func (d *Daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
 		response["last_error_time"] = state.LastErrorTime.Format(time.RFC3339)
 	}
 
+	if d.config.EnableDHT && d.announcer != nil {
+		breaker := d.announcer.BreakerStatus()
+		breakerInfo := map[string]interface{}{
+			"state":                string(breaker.State),
+			"consecutive_failures": breaker.ConsecutiveFailures,
+			"threshold":            breaker.Threshold,
+			"cooldown_seconds":     breaker.Cooldown.Seconds(),
+		}
+		if !breaker.OpenedAt.IsZero() {
+			breakerInfo["opened_at"] = breaker.OpenedAt.Format(time.RFC3339)
+		}
+		response["announce_breaker"] = breakerInfo
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -2,10 +2,20 @@ package daemon
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/anacrolix/torrent/metainfo"
+	"github.com/libreseed/libreseed/pkg/dht"
+	packagetypes "github.com/libreseed/libreseed/pkg/package"
 )
 
 // mockAnnouncer is a test double for the Announcer component
@@ -502,3 +512,392 @@ func TestPackageAdditionOrder(t *testing.T) {
 		}
 	}
 }
+
+// TestNewDHTClientConfig_AnnouncePort verifies the announce port override is applied
+func TestNewDHTClientConfig_AnnouncePort(t *testing.T) {
+	config := DefaultConfig()
+
+	// Without override the announce port follows the listen port
+	dhtConfig := newDHTClientConfig(config)
+	if dhtConfig.Port != config.DHTPort {
+		t.Errorf("expected port %d, got %d", config.DHTPort, dhtConfig.Port)
+	}
+	if dhtConfig.AnnouncePort != config.DHTPort {
+		t.Errorf("expected announce port %d, got %d", config.DHTPort, dhtConfig.AnnouncePort)
+	}
+
+	// With override the forwarded port is advertised
+	config.DHTAnnouncePort = 51413
+	dhtConfig = newDHTClientConfig(config)
+	if dhtConfig.Port != config.DHTPort {
+		t.Errorf("expected port %d, got %d", config.DHTPort, dhtConfig.Port)
+	}
+	if dhtConfig.AnnouncePort != 51413 {
+		t.Errorf("expected announce port 51413, got %d", dhtConfig.AnnouncePort)
+	}
+}
+
+// TestValidate_DHTAnnouncePort verifies the announce port range check
+func TestValidate_DHTAnnouncePort(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    int
+		wantErr bool
+	}{
+		{"default", 0, false},
+		{"low port", 1, false},
+		{"forwarded port", 51413, false},
+		{"max port", 65535, false},
+		{"negative", -1, true},
+		{"too high", 65536, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.DHTAnnouncePort = tt.port
+
+			err := config.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error for port %d", tt.port)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error for port %d: %v", tt.port, err)
+			}
+		})
+	}
+}
+
+// TestSyncAnnouncer_OnlyReleased verifies pending and yanked packages are not announced
+func TestSyncAnnouncer_OnlyReleased(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	states := map[string]PackageState{
+		"released-package": StateReleased,
+		"pending-package":  StatePending,
+		"yanked-package":   StateYanked,
+	}
+	for name, state := range states {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), name, "1.0.0")
+		info.State = state
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{EnableDHT: true},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+		announcer:      dht.NewAnnouncer(nil, time.Hour),
+	}
+
+	d.syncAnnouncer()
+
+	announced := d.announcer.GetPackages()
+	if len(announced) != 1 {
+		t.Fatalf("expected 1 announced package, got %d", len(announced))
+	}
+	if announced[0].PackageName != "released-package" {
+		t.Errorf("expected released-package to be announced, got %s", announced[0].PackageName)
+	}
+}
+
+// TestSyncAnnouncer_DedupByContentHash verifies packages with identical content
+// share one announcement when AnnounceByContentHash is enabled
+func TestSyncAnnouncer_DedupByContentHash(t *testing.T) {
+	for _, dedup := range []bool{false, true} {
+		pm := newTestPackageManager(t)
+
+		// Two packages with the same content, differing only in metadata
+		var ids []string
+		var contentHash string
+		for _, description := range []string{"original build", "repackaged build"} {
+			pkgData, pkg := createTestPackageFileWith(t, func(m *packagetypes.Manifest) {
+				m.Description = description
+				m.ContentHash = packagetypes.ComputeManifestContentHash(m.ContentList)
+			})
+			filePath := filepath.Join(pm.GetStorageDir(), pkg.PackageID+".lspkg")
+			if err := os.WriteFile(filePath, pkgData, 0644); err != nil {
+				t.Fatalf("failed to write package file: %v", err)
+			}
+
+			info := newPackageInfo(pkg, filePath)
+			info.State = StateReleased
+			if err := pm.AddPackage(info); err != nil {
+				t.Fatalf("failed to add package: %v", err)
+			}
+			ids = append(ids, info.PackageID)
+			contentHash = info.FileHash
+		}
+		if ids[0] == ids[1] {
+			t.Fatal("expected packages with different metadata to have different IDs")
+		}
+
+		d := &Daemon{
+			config: &DaemonConfig{
+				EnableDHT:             true,
+				AnnounceByContentHash: dedup,
+				VerifyContentHash:     dedup,
+			},
+			state:          NewDaemonState(),
+			stats:          NewDaemonStatistics(),
+			packageManager: pm,
+			announcer:      dht.NewAnnouncer(nil, time.Hour),
+		}
+
+		d.syncAnnouncer()
+
+		announced := d.announcer.GetPackages()
+		if !dedup {
+			if len(announced) != 2 {
+				t.Errorf("without dedup: expected 2 announcements, got %d", len(announced))
+			}
+			continue
+		}
+
+		if len(announced) != 1 {
+			t.Fatalf("with dedup: expected 1 announcement, got %d", len(announced))
+		}
+		if len(announced[0].PackageIDs) != 2 {
+			t.Errorf("with dedup: expected 2 referencing packages, got %v", announced[0].PackageIDs)
+		}
+		expected, _ := packageInfoHash(contentHash)
+		if announced[0].InfoHash != expected {
+			t.Errorf("with dedup: expected InfoHash %x, got %x", expected, announced[0].InfoHash)
+		}
+		for _, id := range ids {
+			if !slices.Contains(announced[0].PackageIDs, id) {
+				t.Errorf("with dedup: expected %s to reference the announcement", id)
+			}
+		}
+	}
+}
+
+// TestValidate_AnnounceByContentHash verifies announcing by content hash
+// requires content hash verification
+func TestValidate_AnnounceByContentHash(t *testing.T) {
+	config := DefaultConfig()
+	config.AnnounceByContentHash = true
+
+	if err := config.Validate(); err == nil {
+		t.Error("expected error when announce_by_content_hash is set without verify_content_hash")
+	}
+
+	config.VerifyContentHash = true
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// getReady calls /ready and returns the status code and decoded response
+func getReady(t *testing.T, d *Daemon) (int, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w := httptest.NewRecorder()
+	d.handleReady(w, req)
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return w.Code, response
+}
+
+// TestHandleReady_WaitsForDHTBootstrap verifies /ready waits for the DHT to
+// find a node before reporting ready
+func TestHandleReady_WaitsForDHTBootstrap(t *testing.T) {
+	client := &fakeDHTClient{}
+	d := &Daemon{
+		config:    &DaemonConfig{EnableDHT: true},
+		state:     NewDaemonState(),
+		stats:     NewDaemonStatistics(),
+		bootstrap: dht.NewBootstrapWatcher(client, time.Hour),
+	}
+
+	// Not running yet
+	if code, response := getReady(t, d); code != http.StatusServiceUnavailable || response["status"] != "starting" {
+		t.Errorf("expected 503 starting before running, got %d %v", code, response["status"])
+	}
+
+	d.state.SetStatus(StatusRunning)
+	if code, response := getReady(t, d); code != http.StatusServiceUnavailable || response["status"] != "waiting-for-dht" {
+		t.Errorf("expected 503 waiting-for-dht before bootstrap, got %d %v", code, response["status"])
+	}
+
+	client.mu.Lock()
+	client.nodes = 4
+	client.mu.Unlock()
+
+	code, response := getReady(t, d)
+	if code != http.StatusOK || response["ready"] != true || response["status"] != "ready" {
+		t.Errorf("expected 200 ready after bootstrap, got %d %v", code, response)
+	}
+	bootstrap, _ := response["dht_bootstrap"].(map[string]interface{})
+	if bootstrap["state"] != string(dht.BootstrapComplete) || bootstrap["nodes"] != float64(4) {
+		t.Errorf("unexpected dht_bootstrap: %v", response["dht_bootstrap"])
+	}
+}
+
+// TestHandleReady_DegradedAfterTimeout verifies /ready proceeds with a
+// degraded flag when the DHT fails to bootstrap in time
+func TestHandleReady_DegradedAfterTimeout(t *testing.T) {
+	d := &Daemon{
+		config:    &DaemonConfig{EnableDHT: true},
+		state:     NewDaemonState(),
+		stats:     NewDaemonStatistics(),
+		bootstrap: dht.NewBootstrapWatcher(&fakeDHTClient{}, time.Millisecond),
+	}
+	d.state.SetStatus(StatusRunning)
+
+	time.Sleep(5 * time.Millisecond)
+
+	code, response := getReady(t, d)
+	if code != http.StatusOK || response["ready"] != true {
+		t.Errorf("expected 200 ready after timeout, got %d %v", code, response)
+	}
+	if response["status"] != "degraded-dht" {
+		t.Errorf("expected status degraded-dht, got %v", response["status"])
+	}
+}
+
+// TestHandleReady_DHTDisabled verifies readiness does not depend on the DHT when it is disabled
+func TestHandleReady_DHTDisabled(t *testing.T) {
+	d := &Daemon{
+		config: &DaemonConfig{EnableDHT: false},
+		state:  NewDaemonState(),
+		stats:  NewDaemonStatistics(),
+	}
+	d.state.SetStatus(StatusRunning)
+
+	code, response := getReady(t, d)
+	if code != http.StatusOK || response["status"] != "ready" {
+		t.Errorf("expected 200 ready, got %d %v", code, response)
+	}
+	if _, exists := response["dht_bootstrap"]; exists {
+		t.Error("expected no dht_bootstrap section with DHT disabled")
+	}
+}
+
+// TestHandleStatus_AnnounceBreaker verifies the announce breaker state is exposed in /status
+func TestHandleStatus_AnnounceBreaker(t *testing.T) {
+	announcer := dht.NewAnnouncer(nil, time.Hour)
+	announcer.SetCircuitBreaker(3, 2*time.Minute)
+
+	d := &Daemon{
+		config:    &DaemonConfig{EnableDHT: true},
+		state:     NewDaemonState(),
+		stats:     NewDaemonStatistics(),
+		announcer: announcer,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	w := httptest.NewRecorder()
+	d.handleStatus(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	breaker, ok := response["announce_breaker"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected announce_breaker in response, got %v", response["announce_breaker"])
+	}
+	if breaker["state"] != string(dht.BreakerClosed) {
+		t.Errorf("expected breaker state %q, got %v", dht.BreakerClosed, breaker["state"])
+	}
+	if breaker["threshold"] != float64(3) {
+		t.Errorf("expected threshold 3, got %v", breaker["threshold"])
+	}
+	if breaker["cooldown_seconds"] != float64(120) {
+		t.Errorf("expected cooldown_seconds 120, got %v", breaker["cooldown_seconds"])
+	}
+}
+
+// TestNew_DefaultAnnounceBreaker verifies a daemon built from the default
+// configuration has the announce circuit breaker enabled
+func TestNew_DefaultAnnounceBreaker(t *testing.T) {
+	config := DefaultConfig()
+	config.StorageDir = filepath.Join(t.TempDir(), "storage")
+
+	d, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create daemon: %v", err)
+	}
+
+	breaker := d.announcer.BreakerStatus()
+	if breaker.Threshold != dht.DefaultBreakerThreshold {
+		t.Errorf("expected breaker threshold %d, got %d", dht.DefaultBreakerThreshold, breaker.Threshold)
+	}
+	if breaker.Cooldown != dht.DefaultBreakerCooldown {
+		t.Errorf("expected breaker cooldown %v, got %v", dht.DefaultBreakerCooldown, breaker.Cooldown)
+	}
+}
+
+// TestSyncAnnouncementStatus_BreakerOpen verifies packages are marked as not
+// announced while the breaker defers their announces, and announced again
+// once an announce succeeds
+func TestSyncAnnouncementStatus_BreakerOpen(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	info.AnnouncedToDHT = true
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	client := &fakeDHTClient{err: errors.New("no DHT nodes")}
+	announcer := dht.NewAnnouncer(client, time.Hour)
+	announcer.SetCircuitBreaker(1, time.Millisecond)
+
+	d := &Daemon{
+		config:         &DaemonConfig{EnableDHT: true},
+		packageManager: pm,
+		announcer:      announcer,
+	}
+
+	infoHash, _ := packageInfoHash(info.PackageID)
+	announcer.AddPackageRef(infoHash, info.PackageID, info.Name, info.CreatorFingerprint, info.MaintainerFingerprint)
+
+	// The failure opens the breaker; the next announce is deferred
+	announcer.AnnounceNow(infoHash)
+	if err := announcer.AnnounceNow(infoHash); !errors.Is(err, dht.ErrBreakerOpen) {
+		t.Fatalf("expected ErrBreakerOpen, got %v", err)
+	}
+
+	d.syncAnnouncementStatus()
+	if stored, _ := pm.GetPackage(info.PackageID); stored.AnnouncedToDHT {
+		t.Error("expected package marked as not announced while the breaker is open")
+	}
+
+	// After the cooldown a successful probe closes the breaker
+	time.Sleep(5 * time.Millisecond)
+	client.mu.Lock()
+	client.err = nil
+	client.mu.Unlock()
+	if err := announcer.AnnounceNow(infoHash); err != nil {
+		t.Fatalf("expected probe announce to succeed, got %v", err)
+	}
+
+	d.syncAnnouncementStatus()
+	if stored, _ := pm.GetPackage(info.PackageID); !stored.AnnouncedToDHT {
+		t.Error("expected package marked as announced after a successful announce")
+	}
+}
+
+// TestDefaultConfig_AnnounceJitter verifies republishes are spread by default
+func TestDefaultConfig_AnnounceJitter(t *testing.T) {
+	config := DefaultConfig()
+	if config.AnnounceJitter != 5*time.Minute {
+		t.Errorf("expected default announce jitter %v, got %v", 5*time.Minute, config.AnnounceJitter)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("default config failed validation: %v", err)
+	}
+}
@@ -3,12 +3,15 @@ package daemon
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/anacrolix/torrent/metainfo"
@@ -21,6 +24,9 @@ import (
 // Multipart form data:
 // - file: the .lspkg package file (YAML with dual signatures)
 //
+// Query parameters:
+// - receipt=true: include a signed acceptance receipt (or receipt_error if signing fails)
+//
 // The package file must contain:
 // - Manifest with creator and maintainer public keys
 // - ManifestSignature (creator's signature)
@@ -49,6 +55,13 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
+	// Only accept configured package file types
+	if !d.config.IsAllowedExtension(header.Filename) {
+		http.Error(w, fmt.Sprintf("Unsupported file type %q", filepath.Ext(header.Filename)),
+			http.StatusUnsupportedMediaType)
+		return
+	}
+
 	// Read entire file into memory for parsing
 	fileData, err := io.ReadAll(file)
 	if err != nil {
@@ -85,26 +98,21 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("✓ Dual signature verification passed for package %s v%s\n", pkg.Manifest.PackageName, pkg.Manifest.Version)
 
+	// Verify the declared content hash against the signed content list
+	if d.config.VerifyContentHash {
+		if err := pkg.Manifest.VerifyContentHash(); err != nil {
+			http.Error(w, fmt.Sprintf("Content hash verification failed: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Compute creator and maintainer fingerprints
 	creatorFingerprint := pkg.Manifest.CreatorPubKey.Fingerprint()
 	maintainerFingerprint := pkg.Manifest.MaintainerPubKey.Fingerprint()
 
-	// Create PackageInfo from parsed package
-	packageInfo := &PackageInfo{
-		PackageID:                   pkg.PackageID,
-		Name:                        pkg.Manifest.PackageName,
-		Version:                     pkg.Manifest.Version,
-		Description:                 pkg.Manifest.Description,
-		FilePath:                    "", // Will be set after file copy
-		FileHash:                    pkg.Manifest.ContentHash,
-		FileSize:                    pkg.SizeBytes,
-		CreatedAt:                   time.Now(),
-		CreatorFingerprint:          creatorFingerprint,
-		ManifestSignature:           hex.EncodeToString(pkg.ManifestSignature.SignedData),
-		MaintainerFingerprint:       maintainerFingerprint,
-		MaintainerManifestSignature: hex.EncodeToString(pkg.MaintainerManifestSignature.SignedData),
-		AnnouncedToDHT:              false,
-	}
+	// Create PackageInfo from parsed package; FilePath is set after the file copy
+	packageInfo := newPackageInfo(pkg, "")
+	packageInfo.State = StateReleased // Maintainer signature verified above
 
 	// Save .lspkg file to packages directory
 	destPath := filepath.Join(d.packageManager.GetStorageDir(), header.Filename)
@@ -113,8 +121,11 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update FilePath in packageInfo
+	// Update FilePath in packageInfo; the stored file carries the contents
+	// verified above
 	packageInfo.FilePath = destPath
+	packageInfo.Verified = true
+	packageInfo.LastVerifiedAt = time.Now()
 
 	// Save metadata via packageManager
 	if err := d.packageManager.AddPackage(packageInfo); err != nil {
@@ -123,19 +134,42 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Enforce the per-package version limit; an upload older than every
+	// retained version is rejected before anything else is evicted
+	evicted, err := d.packageManager.PruneVersions(packageInfo.Name, d.config.MaxVersionsPerPackage, packageInfo.PackageID)
+	if errors.Is(err, ErrVersionEvicted) {
+		if err := d.packageManager.RemovePackage(packageInfo.PackageID); err != nil {
+			log.Printf("Warning: Failed to remove rejected package %s: %v\n", packageInfo.PackageID, err)
+		}
+		http.Error(w, fmt.Sprintf("Version %s is older than the %d retained versions of %s",
+			packageInfo.Version, d.config.MaxVersionsPerPackage, packageInfo.Name), http.StatusConflict)
+		return
+	}
+	for _, old := range evicted {
+		d.removeFromAnnouncer(old)
+		log.Printf("Evicted %s v%s (limit %d versions per package)\n", old.Name, old.Version, d.config.MaxVersionsPerPackage)
+
+		d.state.mu.Lock()
+		if d.state.ActivePackages > 0 {
+			d.state.ActivePackages--
+		}
+		d.state.mu.Unlock()
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to prune old versions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// Announce to DHT if enabled
 	log.Printf("DHT check - EnableDHT=%v, dhtClient=%v, announcer=%v\n", d.config.EnableDHT, d.dhtClient != nil, d.announcer != nil)
-	if d.config.EnableDHT && d.dhtClient != nil && d.announcer != nil {
+	if d.config.EnableDHT && d.dhtClient != nil && d.announcer != nil && packageInfo.State == StateReleased {
 		log.Printf("Attempting DHT announcement for package %s (ID: %s)\n", packageInfo.Name, packageInfo.PackageID)
-		// Convert package ID (SHA-256 hex) to DHT InfoHash (first 20 bytes)
-		infoHashBytes, err := hex.DecodeString(packageInfo.PackageID[:40])
-		if err == nil && len(infoHashBytes) >= 20 {
-			var infoHash metainfo.Hash
-			copy(infoHash[:], infoHashBytes[:20])
-
-			// Add package to DHT announcer with dual signature fingerprints
-			d.announcer.AddPackage(infoHash, packageInfo.Name, creatorFingerprint, maintainerFingerprint)
-			log.Printf("Called d.announcer.AddPackage for %s with InfoHash %x (Creator: %s, Maintainer: %s)\n",
+		infoHash, err := d.announceInfoHash(packageInfo)
+		if err == nil {
+			// Add package to DHT announcer with dual signature fingerprints;
+			// packages sharing an InfoHash share one announcement
+			d.announcer.AddPackageRef(infoHash, packageInfo.PackageID, packageInfo.Name, creatorFingerprint, maintainerFingerprint)
+			log.Printf("Called d.announcer.AddPackageRef for %s with InfoHash %x (Creator: %s, Maintainer: %s)\n",
 				packageInfo.Name, infoHash, creatorFingerprint, maintainerFingerprint)
 
 			// Update announcement status in package manager
@@ -145,7 +179,7 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 				log.Printf("Successfully updated announcement status for package %s\n", packageInfo.PackageID)
 			}
 
-			log.Printf("Package %s announced to DHT with InfoHash %x\n", packageInfo.Name, pkg.Manifest.ContentHash)
+			log.Printf("Package %s announced to DHT with InfoHash %x\n", packageInfo.Name, infoHash)
 		} else {
 			log.Printf("Warning: Failed to convert package ID to InfoHash: %v\n", err)
 		}
@@ -163,6 +197,11 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 	d.stats.mu.Unlock()
 
 	// Return success response with both fingerprints
+	evictedIDs := make([]string, 0, len(evicted))
+	for _, old := range evicted {
+		evictedIDs = append(evictedIDs, old.PackageID)
+	}
+
 	response := map[string]interface{}{
 		"status":                 "success",
 		"package_id":             packageInfo.PackageID,
@@ -170,23 +209,112 @@ func (d *Daemon) handlePackageAdd(w http.ResponseWriter, r *http.Request) {
 		"maintainer_fingerprint": maintainerFingerprint,
 		"file_hash":              pkg.Manifest.ContentHash,
 		"filename":               header.Filename,
+		"state":                  packageInfo.State,
+		"evicted":                evictedIDs,
 		"verified":               true,
 	}
 
+	// Attach a signed receipt if requested; the package is already stored,
+	// so a signing failure is reported alongside the success response
+	if wantReceipt, _ := strconv.ParseBool(r.URL.Query().Get("receipt")); wantReceipt {
+		receipt, err := NewReceipt(d.keyManager, packageInfo.PackageID, packageInfo.CreatedAt)
+		if err != nil {
+			log.Printf("Warning: Failed to create receipt for %s: %v\n", packageInfo.PackageID, err)
+			response["receipt_error"] = err.Error()
+		} else {
+			response["receipt"] = receipt
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
 // handlePackageList handles package listing requests.
-// GET /packages/list
+// GET /packages/list[?state=pending|released|yanked][&platform=P][&arch=A]
+// Without a state filter, pending and released packages are listed.
+// Platform and arch filters also match packages that declare no target
+// (an empty platform or arch is treated as a wildcard); there is no
+// exact-match mode.
 func (d *Daemon) handlePackageList(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	packages := d.packageManager.ListPackages()
+	wanted := map[PackageState]bool{StatePending: true, StateReleased: true}
+	if val := r.URL.Query().Get("state"); val != "" {
+		state := PackageState(val)
+		if !state.IsValid() {
+			http.Error(w, fmt.Sprintf("Invalid state %q: must be one of pending, released, yanked", val), http.StatusBadRequest)
+			return
+		}
+		wanted = map[PackageState]bool{state: true}
+	}
+
+	platform := r.URL.Query().Get("platform")
+	arch := r.URL.Query().Get("arch")
+
+	packages := make([]*PackageInfo, 0)
+	for _, pkg := range d.packageManager.ListPackages() {
+		if !wanted[pkg.State] {
+			continue
+		}
+		if platform != "" && pkg.Platform != "" && pkg.Platform != platform {
+			continue
+		}
+		if arch != "" && pkg.Arch != "" && pkg.Arch != arch {
+			continue
+		}
+		packages = append(packages, pkg)
+	}
+
+	response := map[string]interface{}{
+		"status":   "success",
+		"count":    len(packages),
+		"packages": packages,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// defaultRecentLimit is the number of packages returned by /packages/recent without a limit
+const defaultRecentLimit = 20
+
+// handlePackageRecent returns the most recently added packages, newest first.
+// GET /packages/recent[?limit=N]
+// Yanked packages are not included.
+func (d *Daemon) handlePackageRecent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := defaultRecentLimit
+	if val := r.URL.Query().Get("limit"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			http.Error(w, fmt.Sprintf("Invalid limit %q: must be a positive integer", val), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	packages := make([]*PackageInfo, 0)
+	for _, pkg := range d.packageManager.ListPackages() {
+		if pkg.State != StateYanked {
+			packages = append(packages, pkg)
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].CreatedAt.After(packages[j].CreatedAt)
+	})
+	if len(packages) > limit {
+		packages = packages[:limit]
+	}
 
 	response := map[string]interface{}{
 		"status":   "success",
@@ -236,20 +364,7 @@ func (d *Daemon) handlePackageRemove(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Remove from DHT if enabled
-	if d.config.EnableDHT && d.dhtClient != nil && d.announcer != nil {
-		// Convert package ID to DHT InfoHash
-		infoHashBytes, err := hex.DecodeString(packageID[:40])
-		if err == nil && len(infoHashBytes) >= 20 {
-			var infoHash metainfo.Hash
-			copy(infoHash[:], infoHashBytes[:20])
-
-			// Remove package from DHT announcer
-			d.announcer.RemovePackage(infoHash)
-			fmt.Printf("Package %s removed from DHT announcements (InfoHash %x)\n", packageInfo.Name, infoHash)
-		} else {
-			fmt.Printf("Warning: Failed to convert package ID to InfoHash for DHT removal: %v\n", err)
-		}
-	}
+	d.removeFromAnnouncer(packageInfo)
 
 	// Remove from package manager (this also deletes the file)
 	if err := d.packageManager.RemovePackage(packageID); err != nil {
@@ -274,3 +389,340 @@ func (d *Daemon) handlePackageRemove(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// removeFromAnnouncer drops a package's reference to its DHT announcement, if
+// DHT is enabled. The InfoHash stops being announced once no package uses it.
+func (d *Daemon) removeFromAnnouncer(pkg *PackageInfo) {
+	if !d.config.EnableDHT || d.announcer == nil {
+		return
+	}
+
+	infoHash, err := d.announceInfoHash(pkg)
+	if err != nil {
+		log.Printf("Warning: Failed to convert package ID to InfoHash for DHT removal: %v\n", err)
+		return
+	}
+	if d.announcer.RemovePackageRef(infoHash, pkg.PackageID) {
+		log.Printf("Package %s removed from DHT announcements (InfoHash %x)\n", pkg.Name, infoHash)
+	} else {
+		log.Printf("Package %s unreferenced; InfoHash %x still announced for other packages\n", pkg.Name, infoHash)
+	}
+}
+
+// handlePackageTouch re-announces a package to the DHT and refreshes its
+// announcement timestamp without re-uploading its content.
+// POST /packages/{id}/touch
+func (d *Daemon) handlePackageTouch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	packageID := r.PathValue("id")
+	packageInfo, exists := d.packageManager.GetPackage(packageID)
+	if !exists {
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return
+	}
+
+	if packageInfo.State != StateReleased {
+		http.Error(w, fmt.Sprintf("Package is %s; only released packages are announced", packageInfo.State), http.StatusConflict)
+		return
+	}
+
+	if !d.config.EnableDHT || d.announcer == nil {
+		http.Error(w, "DHT is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	infoHash, err := d.announceInfoHash(packageInfo)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid package ID: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// AddPackageRef is a no-op if the package is already tracked
+	d.announcer.AddPackageRef(infoHash, packageID, packageInfo.Name, packageInfo.CreatorFingerprint, packageInfo.MaintainerFingerprint)
+	if err := d.announcer.AnnounceNow(infoHash); err != nil {
+		http.Error(w, fmt.Sprintf("DHT announce failed: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	if err := d.packageManager.Touch(packageID); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update package: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	packageInfo, _ = d.packageManager.GetPackage(packageID)
+
+	response := map[string]interface{}{
+		"status":         "success",
+		"package_id":     packageID,
+		"last_announced": packageInfo.LastAnnounced.Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handlePackagePromote releases a pending package and starts announcing it.
+// POST /packages/{id}/promote
+func (d *Daemon) handlePackagePromote(w http.ResponseWriter, r *http.Request) {
+	d.transitionPackageState(w, r, StateReleased, StatePending)
+}
+
+// handlePackageYank withdraws a pending or released package and stops
+// announcing it. The package file is kept.
+// POST /packages/{id}/yank
+func (d *Daemon) handlePackageYank(w http.ResponseWriter, r *http.Request) {
+	d.transitionPackageState(w, r, StateYanked, StatePending, StateReleased)
+}
+
+// transitionPackageState moves a package to the target release state if it is
+// currently in one of the from states, and updates its DHT announcement to
+// match. Packages in any other state are rejected with 409.
+func (d *Daemon) transitionPackageState(w http.ResponseWriter, r *http.Request, target PackageState, from ...PackageState) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	packageID := r.PathValue("id")
+	packageInfo, exists := d.packageManager.GetPackage(packageID)
+	if !exists {
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return
+	}
+
+	previous := packageInfo.State
+	allowed := false
+	for _, state := range from {
+		if previous == state {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		http.Error(w, fmt.Sprintf("Package is %s; cannot change it to %s", previous, target), http.StatusConflict)
+		return
+	}
+
+	if err := d.packageManager.SetPackageState(packageID, target); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update package: %v", err), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Package %s v%s changed from %s to %s\n", packageInfo.Name, packageInfo.Version, previous, target)
+
+	switch {
+	case target == StateReleased:
+		d.addToAnnouncer(packageInfo)
+	case previous == StateReleased:
+		d.removeFromAnnouncer(packageInfo)
+		if err := d.packageManager.UpdateAnnouncementStatus(packageID, false); err != nil {
+			log.Printf("Warning: Failed to update announcement status: %v\n", err)
+		}
+	}
+
+	response := map[string]interface{}{
+		"status":         "success",
+		"package_id":     packageID,
+		"previous_state": previous,
+		"state":          target,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// addToAnnouncer starts announcing a released package to the DHT, if DHT is
+// enabled, and records it as announced.
+func (d *Daemon) addToAnnouncer(pkg *PackageInfo) {
+	if !d.config.EnableDHT || d.announcer == nil {
+		return
+	}
+
+	infoHash, err := d.announceInfoHash(pkg)
+	if err != nil {
+		log.Printf("Warning: Failed to convert package ID to InfoHash: %v\n", err)
+		return
+	}
+	d.announcer.AddPackageRef(infoHash, pkg.PackageID, pkg.Name, pkg.CreatorFingerprint, pkg.MaintainerFingerprint)
+	if err := d.packageManager.UpdateAnnouncementStatus(pkg.PackageID, true); err != nil {
+		log.Printf("Warning: Failed to update announcement status: %v\n", err)
+	}
+	log.Printf("Package %s announced to DHT with InfoHash %x\n", pkg.Name, infoHash)
+}
+
+// handlePackageVerify re-verifies the stored file of a package and updates
+// its cached verification status.
+// POST /packages/{id}/verify
+func (d *Daemon) handlePackageVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	packageID := r.PathValue("id")
+	if !d.packageManager.PackageExists(packageID) {
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return
+	}
+
+	verified, err := d.packageManager.VerifyPackage(packageID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to verify package: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	packageInfo, _ := d.packageManager.GetPackage(packageID)
+
+	response := map[string]interface{}{
+		"status":           "success",
+		"package_id":       packageID,
+		"verified":         verified,
+		"last_verified_at": packageInfo.LastVerifiedAt.Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handlePackageScrub re-verifies the stored files of all packages.
+// POST /packages/scrub
+func (d *Daemon) handlePackageScrub(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	checked, failed, err := d.packageManager.Scrub()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to scrub packages: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"status":  "success",
+		"checked": checked,
+		"failed":  failed,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handlePackageDependencies resolves a package's declared dependencies
+// against the packages held locally.
+// GET /packages/{id}/dependencies/resolve
+//
+// Each dependency is reported as "satisfied" (with the highest matching
+// local version) or "missing". Only released packages, the ones listed in
+// the index, satisfy a dependency.
+func (d *Daemon) handlePackageDependencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	packageID := r.PathValue("id")
+	packageInfo, exists := d.packageManager.GetPackage(packageID)
+	if !exists {
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return
+	}
+
+	pkg, err := packagetypes.LoadPackageFromFile(packageInfo.FilePath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load package: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Index local candidates by name
+	candidates := make(map[string][]*PackageInfo)
+	for _, local := range d.packageManager.ListPackages() {
+		if local.State == StateReleased {
+			candidates[local.Name] = append(candidates[local.Name], local)
+		}
+	}
+
+	allSatisfied := true
+	resolved := make([]map[string]interface{}, 0, len(pkg.Manifest.Dependencies))
+	for _, dep := range pkg.Manifest.Dependencies {
+		entry := map[string]interface{}{
+			"package_name":       dep.PackageName,
+			"version_constraint": dep.VersionConstraint,
+			"optional":           dep.Optional,
+		}
+
+		constraint, err := packagetypes.ParseConstraint(dep.VersionConstraint)
+		if err != nil {
+			entry["status"] = "invalid"
+			entry["error"] = err.Error()
+			if !dep.Optional {
+				allSatisfied = false
+			}
+			resolved = append(resolved, entry)
+			continue
+		}
+
+		var best *PackageInfo
+		for _, candidate := range candidates[dep.PackageName] {
+			version, err := packagetypes.ParseSemVer(candidate.Version)
+			if err != nil || !constraint.Matches(version) {
+				continue
+			}
+			if best == nil || packagetypes.CompareVersions(candidate.Version, best.Version) > 0 {
+				best = candidate
+			}
+		}
+
+		if best == nil {
+			entry["status"] = "missing"
+			if !dep.Optional {
+				allSatisfied = false
+			}
+		} else {
+			entry["status"] = "satisfied"
+			entry["package_id"] = best.PackageID
+			entry["version"] = best.Version
+		}
+		resolved = append(resolved, entry)
+	}
+
+	response := map[string]interface{}{
+		"status":       "success",
+		"package_id":   packageID,
+		"satisfied":    allSatisfied,
+		"dependencies": resolved,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// announceInfoHash returns the DHT InfoHash a package is announced under:
+// derived from its manifest content hash when AnnounceByContentHash is set,
+// from its package ID otherwise. Config validation only allows the former
+// together with VerifyContentHash, so the hash used as key has been checked.
+func (d *Daemon) announceInfoHash(pkg *PackageInfo) (metainfo.Hash, error) {
+	if d.config.AnnounceByContentHash {
+		return packageInfoHash(pkg.FileHash)
+	}
+	return packageInfoHash(pkg.PackageID)
+}
+
+// packageInfoHash converts a package ID (SHA-256 hex) to its DHT InfoHash (first 20 bytes).
+func packageInfoHash(packageID string) (metainfo.Hash, error) {
+	var infoHash metainfo.Hash
+
+	if len(packageID) < 40 {
+		return infoHash, fmt.Errorf("package ID %q too short", packageID)
+	}
+	infoHashBytes, err := hex.DecodeString(packageID[:40])
+	if err != nil {
+		return infoHash, err
+	}
+
+	copy(infoHash[:], infoHashBytes)
+	return infoHash, nil
+}
@@ -8,21 +8,31 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/libreseed/libreseed/pkg/crypto"
+	"github.com/libreseed/libreseed/pkg/dht"
 	packagetypes "github.com/libreseed/libreseed/pkg/package"
 )
 
 // createTestPackageFile creates a valid .lspkg file for testing
 func createTestPackageFile(t *testing.T) ([]byte, *packagetypes.Package) {
 	t.Helper()
+	return createTestPackageFileWith(t, nil)
+}
+
+// createTestPackageFileWith creates a valid .lspkg file, letting the caller
+// adjust the manifest before it is signed
+func createTestPackageFileWith(t *testing.T, mutate func(*packagetypes.Manifest)) ([]byte, *packagetypes.Package) {
+	t.Helper()
 
 	// Create temporary keys directory
 	tempDir := t.TempDir()
@@ -72,6 +82,9 @@ func createTestPackageFile(t *testing.T) ([]byte, *packagetypes.Package) {
 		},
 		CreatedAt: time.Now(),
 	}
+	if mutate != nil {
+		mutate(manifest)
+	}
 
 	// Serialize manifest for signing
 	manifestData, err := packagetypes.SerializeManifest(manifest)
@@ -365,6 +378,18 @@ func TestHandlePackageAdd_DHTDisabled(t *testing.T) {
 	if statsSnapshot.TotalPackagesSeeded != 1 {
 		t.Errorf("expected TotalPackagesSeeded=1, got %d", statsSnapshot.TotalPackagesSeeded)
 	}
+
+	// Verified maintainer signature releases the package
+	if response["state"] != string(StateReleased) {
+		t.Errorf("expected state %q, got %v", StateReleased, response["state"])
+	}
+	stored, exists := pm.GetPackage(pkg.PackageID)
+	if !exists {
+		t.Fatal("package not stored")
+	}
+	if stored.State != StateReleased {
+		t.Errorf("expected stored state %q, got %q", StateReleased, stored.State)
+	}
 }
 
 // TestHandlePackageAdd_DHTEnabled tests successful package add with DHT enabled
@@ -573,6 +598,781 @@ func TestHandlePackageList_MultiplePackages(t *testing.T) {
 	}
 }
 
+// TestHandlePackageList_StateFilter tests filtering the package list by release state
+func TestHandlePackageList_StateFilter(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	states := map[string]PackageState{
+		"pending-package":  StatePending,
+		"released-package": StateReleased,
+		"yanked-package":   StateYanked,
+	}
+	for name, state := range states {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), name, "1.0.0")
+		info.State = state
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"pending-package", "released-package"}},
+		{"?state=pending", []string{"pending-package"}},
+		{"?state=released", []string{"released-package"}},
+		{"?state=yanked", []string{"yanked-package"}},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/packages/list"+tt.query, nil)
+		w := httptest.NewRecorder()
+
+		d.handlePackageList(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%q: expected status %d, got %d", tt.query, http.StatusOK, w.Code)
+			continue
+		}
+
+		var response struct {
+			Count    int            `json:"count"`
+			Packages []*PackageInfo `json:"packages"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", tt.query, err)
+		}
+
+		if response.Count != len(tt.expected) {
+			t.Errorf("%q: expected count=%d, got %d", tt.query, len(tt.expected), response.Count)
+		}
+		names := make(map[string]bool)
+		for _, pkg := range response.Packages {
+			names[pkg.Name] = true
+		}
+		for _, name := range tt.expected {
+			if !names[name] {
+				t.Errorf("%q: expected %s in response", tt.query, name)
+			}
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/packages/list?state=bogus", nil)
+	w := httptest.NewRecorder()
+	d.handlePackageList(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid state, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// TestHandlePackageList_PlatformFilter tests filtering the package list by target platform and arch
+func TestHandlePackageList_PlatformFilter(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	targets := map[string][2]string{
+		"linux-amd64":  {"linux", "amd64"},
+		"linux-arm64":  {"linux", "arm64"},
+		"darwin-arm64": {"darwin", "arm64"},
+		"portable":     {"", ""},
+	}
+	for name, target := range targets {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), name, "1.0.0")
+		info.Platform, info.Arch = target[0], target[1]
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"linux-amd64", "linux-arm64", "darwin-arm64", "portable"}},
+		{"?platform=linux", []string{"linux-amd64", "linux-arm64", "portable"}},
+		{"?arch=arm64", []string{"linux-arm64", "darwin-arm64", "portable"}},
+		{"?platform=linux&arch=amd64", []string{"linux-amd64", "portable"}},
+		{"?platform=windows", []string{"portable"}},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/packages/list"+tt.query, nil)
+		w := httptest.NewRecorder()
+
+		d.handlePackageList(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%q: expected status %d, got %d", tt.query, http.StatusOK, w.Code)
+			continue
+		}
+
+		var response struct {
+			Count    int            `json:"count"`
+			Packages []*PackageInfo `json:"packages"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", tt.query, err)
+		}
+
+		if response.Count != len(tt.expected) {
+			t.Errorf("%q: expected count=%d, got %d", tt.query, len(tt.expected), response.Count)
+		}
+		got := make(map[string]*PackageInfo)
+		for _, pkg := range response.Packages {
+			got[pkg.Name] = pkg
+		}
+		for _, name := range tt.expected {
+			pkg, ok := got[name]
+			if !ok {
+				t.Errorf("%q: expected %s in response", tt.query, name)
+				continue
+			}
+			if pkg.Platform != targets[name][0] || pkg.Arch != targets[name][1] {
+				t.Errorf("%q: %s target = %s/%s, want %s/%s", tt.query, name,
+					pkg.Platform, pkg.Arch, targets[name][0], targets[name][1])
+			}
+		}
+	}
+
+	// Target fields survive a round trip through packages.yaml
+	reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+	if err := reloaded.LoadState(); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	for _, pkg := range reloaded.ListPackages() {
+		if pkg.Platform != targets[pkg.Name][0] || pkg.Arch != targets[pkg.Name][1] {
+			t.Errorf("reloaded %s target = %s/%s, want %s/%s", pkg.Name,
+				pkg.Platform, pkg.Arch, targets[pkg.Name][0], targets[pkg.Name][1])
+		}
+	}
+}
+
+// TestHandlePackageRecent tests the recently added feed ordering and limit
+func TestHandlePackageRecent(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	// Insert out of chronological order
+	base := time.Now().Add(-time.Hour)
+	offsets := map[string]time.Duration{
+		"package-b": 2 * time.Minute,
+		"package-d": 4 * time.Minute,
+		"package-a": 1 * time.Minute,
+		"package-c": 3 * time.Minute,
+	}
+	for name, offset := range offsets {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), name, "1.0.0")
+		info.CreatedAt = base.Add(offset)
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	yanked := newTestPackageInfo(t, pm.GetStorageDir(), "package-yanked", "1.0.0")
+	yanked.CreatedAt = base.Add(10 * time.Minute)
+	yanked.State = StateYanked
+	if err := pm.AddPackage(yanked); err != nil {
+		t.Fatalf("failed to add yanked package: %v", err)
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"package-d", "package-c", "package-b", "package-a"}},
+		{"?limit=2", []string{"package-d", "package-c"}},
+		{"?limit=10", []string{"package-d", "package-c", "package-b", "package-a"}},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/packages/recent"+tt.query, nil)
+		w := httptest.NewRecorder()
+
+		d.handlePackageRecent(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%q: expected status %d, got %d", tt.query, http.StatusOK, w.Code)
+			continue
+		}
+
+		var response struct {
+			Count    int            `json:"count"`
+			Packages []*PackageInfo `json:"packages"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", tt.query, err)
+		}
+
+		if response.Count != len(tt.expected) || len(response.Packages) != len(tt.expected) {
+			t.Errorf("%q: expected %d packages, got %d", tt.query, len(tt.expected), len(response.Packages))
+			continue
+		}
+		for i, name := range tt.expected {
+			if response.Packages[i].Name != name {
+				t.Errorf("%q: expected %s at position %d, got %s", tt.query, name, i, response.Packages[i].Name)
+			}
+		}
+	}
+
+	for _, limit := range []string{"0", "-1", "abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/packages/recent?limit="+limit, nil)
+		w := httptest.NewRecorder()
+		d.handlePackageRecent(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("limit=%s: expected status %d, got %d", limit, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+// fakeDHTClient is a minimal dht.DHTClient recording announces
+type fakeDHTClient struct {
+	mu        sync.Mutex
+	announced [][20]byte
+	nodes     int
+	err       error // returned by Announce when set
+}
+
+func (f *fakeDHTClient) Start() error { return nil }
+func (f *fakeDHTClient) Stop() error  { return nil }
+
+func (f *fakeDHTClient) Announce(infoHash [20]byte, port int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.announced = append(f.announced, infoHash)
+	return f.err
+}
+
+func (f *fakeDHTClient) GetPeers(infoHash [20]byte) ([]net.Addr, error) { return nil, nil }
+
+func (f *fakeDHTClient) GetStats() dht.ClientStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return dht.ClientStats{NodesInRoutingTable: f.nodes}
+}
+
+func (f *fakeDHTClient) NodeID() [20]byte { return [20]byte{} }
+func (f *fakeDHTClient) IsStarted() bool  { return true }
+
+// TestHandlePackageTouch tests that touch re-announces and refreshes the timestamp
+func TestHandlePackageTouch(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	info.State = StateReleased
+	info.LastAnnounced = time.Now().Add(-time.Hour)
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	client := &fakeDHTClient{}
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: true},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+		announcer:      dht.NewAnnouncer(client, time.Hour),
+	}
+
+	before := time.Now()
+	req := httptest.NewRequest(http.MethodPost, "/packages/"+info.PackageID+"/touch", nil)
+	req.SetPathValue("id", info.PackageID)
+	w := httptest.NewRecorder()
+
+	d.handlePackageTouch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	expectedHash, _ := packageInfoHash(info.PackageID)
+	if len(client.announced) != 1 || client.announced[0] != expectedHash {
+		t.Errorf("expected one announce for %x, got %x", expectedHash, client.announced)
+	}
+
+	stored, _ := pm.GetPackage(info.PackageID)
+	if stored.LastAnnounced.Before(before) {
+		t.Errorf("expected LastAnnounced refreshed, got %v", stored.LastAnnounced)
+	}
+	if stored.FileHash != info.FileHash || stored.Version != info.Version {
+		t.Error("touch must not change package content")
+	}
+}
+
+// TestHandlePackageTouch_Errors tests touch error responses
+func TestHandlePackageTouch_Errors(t *testing.T) {
+	pm := newTestPackageManager(t)
+	pending := newTestPackageInfo(t, pm.GetStorageDir(), "pending-package", "1.0.0")
+	pending.State = StatePending
+	if err := pm.AddPackage(pending); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	client := &fakeDHTClient{}
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: true},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+		announcer:      dht.NewAnnouncer(client, time.Hour),
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		expected int
+	}{
+		{"unknown package", strings.Repeat("0", 64), http.StatusNotFound},
+		{"pending package", pending.PackageID, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/packages/"+tt.id+"/touch", nil)
+		req.SetPathValue("id", tt.id)
+		w := httptest.NewRecorder()
+
+		d.handlePackageTouch(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, w.Code)
+		}
+	}
+
+	if len(client.announced) != 0 {
+		t.Errorf("expected no announces, got %d", len(client.announced))
+	}
+}
+
+// TestHandlePackagePromoteAndYank tests the pending -> released -> yanked
+// lifecycle and that the DHT announcement follows the state
+func TestHandlePackagePromoteAndYank(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	info.State = StatePending
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: true},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+		announcer:      dht.NewAnnouncer(&fakeDHTClient{}, time.Hour),
+	}
+	infoHash, _ := packageInfoHash(info.PackageID)
+
+	transition := func(action string, handler http.HandlerFunc) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/packages/"+info.PackageID+"/"+action, nil)
+		req.SetPathValue("id", info.PackageID)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Code
+	}
+
+	if code := transition("yank", d.handlePackageYank); code != http.StatusOK {
+		t.Fatalf("expected pending package to be yankable, got %d", code)
+	}
+	if code := transition("promote", d.handlePackagePromote); code != http.StatusConflict {
+		t.Errorf("expected yanked package promote to return %d, got %d", http.StatusConflict, code)
+	}
+
+	if err := pm.SetPackageState(info.PackageID, StatePending); err != nil {
+		t.Fatalf("failed to reset state: %v", err)
+	}
+	if code := transition("promote", d.handlePackagePromote); code != http.StatusOK {
+		t.Fatalf("expected promote to return %d, got %d", http.StatusOK, code)
+	}
+	stored, _ := pm.GetPackage(info.PackageID)
+	if stored.State != StateReleased || !stored.AnnouncedToDHT {
+		t.Errorf("expected released and announced, got state %q announced %v", stored.State, stored.AnnouncedToDHT)
+	}
+	if _, ok := d.announcer.GetPackage(infoHash); !ok {
+		t.Error("expected promoted package to be announced")
+	}
+
+	if code := transition("promote", d.handlePackagePromote); code != http.StatusConflict {
+		t.Errorf("expected second promote to return %d, got %d", http.StatusConflict, code)
+	}
+
+	if code := transition("yank", d.handlePackageYank); code != http.StatusOK {
+		t.Fatalf("expected yank to return %d, got %d", http.StatusOK, code)
+	}
+	stored, _ = pm.GetPackage(info.PackageID)
+	if stored.State != StateYanked || stored.AnnouncedToDHT {
+		t.Errorf("expected yanked and not announced, got state %q announced %v", stored.State, stored.AnnouncedToDHT)
+	}
+	if _, ok := d.announcer.GetPackage(infoHash); ok {
+		t.Error("expected yanked package to stop being announced")
+	}
+
+	// The state survives a restart
+	reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+	if err := reloaded.LoadState(); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if stored, _ := reloaded.GetPackage(info.PackageID); stored.State != StateYanked {
+		t.Errorf("expected reloaded state %q, got %q", StateYanked, stored.State)
+	}
+}
+
+// TestHandlePackageVerify tests that an added package is verified and that a
+// corrupted file flips the cached status on reverify and scrub
+func TestHandlePackageVerify(t *testing.T) {
+	pm := newTestPackageManager(t)
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+	}
+
+	pkgData, pkg := createTestPackageFile(t)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "test.lspkg")
+	part.Write(pkgData)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/add", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	d.handlePackageAdd(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to add package: %d %s", w.Code, w.Body.String())
+	}
+
+	stored, _ := pm.GetPackage(pkg.PackageID)
+	if !stored.Verified || stored.LastVerifiedAt.IsZero() {
+		t.Fatalf("expected freshly added package to be verified, got Verified=%v LastVerifiedAt=%v",
+			stored.Verified, stored.LastVerifiedAt)
+	}
+
+	verify := func() map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/packages/"+pkg.PackageID+"/verify", nil)
+		req.SetPathValue("id", pkg.PackageID)
+		w := httptest.NewRecorder()
+		d.handlePackageVerify(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	if response := verify(); response["verified"] != true {
+		t.Errorf("expected intact package to verify, got %v", response["verified"])
+	}
+
+	// Corrupt the stored file
+	if err := os.WriteFile(stored.FilePath, append(pkgData[:len(pkgData)/2:len(pkgData)/2], "garbage"...), 0644); err != nil {
+		t.Fatalf("failed to corrupt package file: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/packages/scrub", nil)
+	w = httptest.NewRecorder()
+	d.handlePackageScrub(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var scrub map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&scrub); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if scrub["checked"] != float64(1) {
+		t.Errorf("expected scrub to check 1 package, got %v", scrub["checked"])
+	}
+	failed, _ := scrub["failed"].([]interface{})
+	if len(failed) != 1 || failed[0] != pkg.PackageID {
+		t.Errorf("expected scrub to report %s as failed, got %v", pkg.PackageID, scrub["failed"])
+	}
+
+	stored, _ = pm.GetPackage(pkg.PackageID)
+	if stored.Verified {
+		t.Error("expected corrupted package to be marked unverified after scrub")
+	}
+
+	if response := verify(); response["verified"] != false {
+		t.Errorf("expected corrupted package to fail reverify, got %v", response["verified"])
+	}
+}
+
+// TestHandlePackageVerify_NotFound tests that verifying an unknown package returns 404
+func TestHandlePackageVerify_NotFound(t *testing.T) {
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0"},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: newTestPackageManager(t),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/missing/verify", nil)
+	req.SetPathValue("id", "missing")
+	w := httptest.NewRecorder()
+
+	d.handlePackageVerify(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// TestHandlePackageDependencies tests resolving dependencies against local packages
+func TestHandlePackageDependencies(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	locals := []struct {
+		name    string
+		version string
+		state   PackageState
+	}{
+		{"lib-a", "1.2.0", StateReleased},
+		{"lib-a", "1.5.0", StateReleased},
+		{"lib-a", "2.0.0", StateReleased},
+		{"lib-a", "1.9.0", StatePending},
+		{"lib-a", "1.6.0-beta.1", StateReleased},
+		{"lib-b", "0.9.0", StateReleased},
+		{"lib-c", "1.0.0", StateYanked},
+	}
+	ids := make(map[string]string)
+	for _, local := range locals {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), local.name, local.version)
+		info.State = local.state
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s@%s: %v", local.name, local.version, err)
+		}
+		ids[local.name+"@"+local.version] = info.PackageID
+	}
+
+	// Build a package declaring dependencies
+	_, pkg := createTestPackageFile(t)
+	pkg.Manifest.Dependencies = []packagetypes.Dependency{
+		{PackageName: "lib-a", VersionConstraint: "^1.0.0"},
+		{PackageName: "lib-b", VersionConstraint: ">=1.0.0"},
+		{PackageName: "lib-c", VersionConstraint: "*"},
+		{PackageName: "lib-a", VersionConstraint: ">=one"},
+	}
+	pkgData, err := packagetypes.SerializePackage(pkg)
+	if err != nil {
+		t.Fatalf("failed to serialize package: %v", err)
+	}
+
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "app", "1.0.0")
+	if err := os.WriteFile(info.FilePath, pkgData, 0644); err != nil {
+		t.Fatalf("failed to write package file: %v", err)
+	}
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add app: %v", err)
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: pm,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/packages/"+info.PackageID+"/dependencies/resolve", nil)
+	req.SetPathValue("id", info.PackageID)
+	w := httptest.NewRecorder()
+
+	d.handlePackageDependencies(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Satisfied    bool                     `json:"satisfied"`
+		Dependencies []map[string]interface{} `json:"dependencies"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Satisfied {
+		t.Error("expected satisfied=false with a missing required dependency")
+	}
+	if len(response.Dependencies) != 4 {
+		t.Fatalf("expected 4 dependencies, got %d", len(response.Dependencies))
+	}
+
+	// Highest matching version wins; pending packages and prereleases
+	// outside the range are not candidates
+	libA := response.Dependencies[0]
+	if libA["status"] != "satisfied" || libA["version"] != "1.5.0" || libA["package_id"] != ids["lib-a@1.5.0"] {
+		t.Errorf("expected lib-a satisfied by 1.5.0, got %v", libA)
+	}
+
+	// Local version outside the range
+	if status := response.Dependencies[1]["status"]; status != "missing" {
+		t.Errorf("expected lib-b missing, got %v", status)
+	}
+
+	// Yanked packages never satisfy a dependency
+	if status := response.Dependencies[2]["status"]; status != "missing" {
+		t.Errorf("expected lib-c missing, got %v", status)
+	}
+
+	// Ranges that cannot be parsed are reported, not matched
+	if invalid := response.Dependencies[3]; invalid["status"] != "invalid" || invalid["error"] == nil {
+		t.Errorf("expected unparseable range reported invalid, got %v", invalid)
+	}
+}
+
+// TestHandlePackageDependencies_NotFound tests resolving an unknown package
+func TestHandlePackageDependencies_NotFound(t *testing.T) {
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: newTestPackageManager(t),
+	}
+
+	id := strings.Repeat("0", 64)
+	req := httptest.NewRequest(http.MethodGet, "/packages/"+id+"/dependencies/resolve", nil)
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+
+	d.handlePackageDependencies(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// TestHandlePackageAdd_ContentHashVerification tests content hash checking on add
+func TestHandlePackageAdd_ContentHashVerification(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(*packagetypes.Manifest)
+		expected int
+	}{
+		{
+			name: "matching content hash",
+			mutate: func(m *packagetypes.Manifest) {
+				m.ContentHash = packagetypes.ComputeManifestContentHash(m.ContentList)
+			},
+			expected: http.StatusCreated,
+		},
+		{
+			name:     "mismatched content hash",
+			mutate:   nil,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Daemon{
+				config: &DaemonConfig{
+					ListenAddr:        "127.0.0.1:0",
+					EnableDHT:         false,
+					VerifyContentHash: true,
+				},
+				state:          NewDaemonState(),
+				stats:          NewDaemonStatistics(),
+				packageManager: newTestPackageManager(t),
+			}
+
+			pkgData, _ := createTestPackageFileWith(t, tt.mutate)
+
+			var buf bytes.Buffer
+			writer := multipart.NewWriter(&buf)
+			part, _ := writer.CreateFormFile("file", "test.lspkg")
+			part.Write(pkgData)
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/packages/add", &buf)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+
+			d.handlePackageAdd(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestHandlePackageAdd_AllowedExtensions tests the uploaded filename extension allowlist
+func TestHandlePackageAdd_AllowedExtensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		filename   string
+		extensions []string
+		expected   int
+	}{
+		{"default allows lspkg", "test.lspkg", nil, http.StatusCreated},
+		{"default is case-insensitive", "test.LSPKG", nil, http.StatusCreated},
+		{"default rejects other extension", "test.tar.gz", nil, http.StatusUnsupportedMediaType},
+		{"default rejects missing extension", "test", nil, http.StatusUnsupportedMediaType},
+		{"custom list allows its extension", "test.pkg", []string{".lspkg", ".pkg"}, http.StatusCreated},
+		{"custom list rejects lspkg when omitted", "test.lspkg", []string{".pkg"}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Daemon{
+				config: &DaemonConfig{
+					ListenAddr:        "127.0.0.1:0",
+					EnableDHT:         false,
+					AllowedExtensions: tt.extensions,
+				},
+				state:          NewDaemonState(),
+				stats:          NewDaemonStatistics(),
+				packageManager: newTestPackageManager(t),
+			}
+
+			pkgData, _ := createTestPackageFile(t)
+
+			var buf bytes.Buffer
+			writer := multipart.NewWriter(&buf)
+			part, _ := writer.CreateFormFile("file", tt.filename)
+			part.Write(pkgData)
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/packages/add", &buf)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+
+			d.handlePackageAdd(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 // TestHandlePackageRemove_InvalidMethod tests that invalid methods return 405
 func TestHandlePackageRemove_InvalidMethod(t *testing.T) {
 	methods := []string{http.MethodGet, http.MethodPut, http.MethodPatch}
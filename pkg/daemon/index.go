@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/libreseed/libreseed/pkg/crypto"
+)
+
+// indexDomain is the signing domain of the index, so index signatures cannot
+// be confused with receipt signatures made by the same daemon key.
+const indexDomain = "libreseed-index:v1"
+
+// PackageIndex is the catalog of released packages offered by this daemon.
+type PackageIndex struct {
+	// GeneratedAt is when the index was built (UTC)
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// SeederID is the fingerprint of the daemon's public key
+	SeederID string `json:"seeder_id"`
+
+	// Packages lists released packages sorted by package ID
+	Packages []IndexEntry `json:"packages"`
+}
+
+// IndexEntry describes a single package in the index.
+type IndexEntry struct {
+	PackageID             string    `json:"package_id"`
+	Name                  string    `json:"name"`
+	Version               string    `json:"version"`
+	Description           string    `json:"description"`
+	Platform              string    `json:"platform,omitempty"`
+	Arch                  string    `json:"arch,omitempty"`
+	FileHash              string    `json:"file_hash"`
+	FileSize              int64     `json:"file_size"`
+	CreatedAt             time.Time `json:"created_at"`
+	CreatorFingerprint    string    `json:"creator_fingerprint"`
+	MaintainerFingerprint string    `json:"maintainer_fingerprint"`
+}
+
+// SignedIndex is the /index.json response body.
+// Signature covers the exact bytes of Index.
+type SignedIndex struct {
+	Index     json.RawMessage `json:"index"`
+	Signature string          `json:"signature"`
+}
+
+// buildPackageIndex builds the index of released packages.
+func (d *Daemon) buildPackageIndex(now time.Time) *PackageIndex {
+	index := &PackageIndex{
+		GeneratedAt: now.UTC(),
+		SeederID:    d.keyManager.Fingerprint(),
+		Packages:    make([]IndexEntry, 0),
+	}
+
+	for _, pkg := range d.packageManager.ListPackages() {
+		if pkg.State != StateReleased {
+			continue
+		}
+		index.Packages = append(index.Packages, IndexEntry{
+			PackageID:             pkg.PackageID,
+			Name:                  pkg.Name,
+			Version:               pkg.Version,
+			Description:           pkg.Description,
+			Platform:              pkg.Platform,
+			Arch:                  pkg.Arch,
+			FileHash:              pkg.FileHash,
+			FileSize:              pkg.FileSize,
+			CreatedAt:             pkg.CreatedAt.UTC(),
+			CreatorFingerprint:    pkg.CreatorFingerprint,
+			MaintainerFingerprint: pkg.MaintainerFingerprint,
+		})
+	}
+
+	// Stable order so identical catalogs serialize identically
+	sort.Slice(index.Packages, func(i, j int) bool {
+		return index.Packages[i].PackageID < index.Packages[j].PackageID
+	})
+
+	return index
+}
+
+// signPackageIndex serializes the index and signs it with the daemon key.
+func signPackageIndex(keyManager *crypto.KeyManager, index *PackageIndex) (*SignedIndex, error) {
+	if keyManager == nil || keyManager.PrivateKey() == nil {
+		return nil, fmt.Errorf("daemon signing key not available")
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize index: %w", err)
+	}
+
+	signature, err := keyManager.SignDomain(indexDomain, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign index: %w", err)
+	}
+
+	return &SignedIndex{
+		Index:     data,
+		Signature: hex.EncodeToString(signature),
+	}, nil
+}
+
+// VerifyIndex checks a signed index against the daemon's public key and
+// returns the decoded index.
+//
+// Parameters:
+//   - signed: the /index.json response body
+//   - publicKey: the daemon public key (as published at /identity)
+//
+// Returns the decoded index, or error if the signature is invalid.
+func VerifyIndex(signed *SignedIndex, publicKey *crypto.PublicKey) (*PackageIndex, error) {
+	if publicKey == nil {
+		return nil, crypto.ErrNilPublicKey
+	}
+
+	signature, err := hex.DecodeString(signed.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("index signature must be valid hex: %w", err)
+	}
+
+	if !crypto.VerifyDomain(publicKey, indexDomain, signed.Index, signature) {
+		return nil, crypto.ErrInvalidSignature
+	}
+
+	var index PackageIndex
+	if err := json.Unmarshal(signed.Index, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+
+	if index.SeederID != publicKey.Fingerprint() {
+		return nil, fmt.Errorf("index seeder_id %s does not match key fingerprint %s", index.SeederID, publicKey.Fingerprint())
+	}
+
+	return &index, nil
+}
+
+// handleIndex returns the signed catalog of released packages.
+// GET /index.json
+func (d *Daemon) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if d.keyManager == nil || d.keyManager.PrivateKey() == nil {
+		http.Error(w, "Daemon keys not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	signed, err := signPackageIndex(d.keyManager, d.buildPackageIndex(time.Now()))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to sign index: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(signed)
+}
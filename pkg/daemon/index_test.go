@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fetchIndex calls /index.json and decodes the signed index
+func fetchIndex(t *testing.T, d *Daemon) *SignedIndex {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/index.json", nil)
+	w := httptest.NewRecorder()
+	d.handleIndex(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var signed SignedIndex
+	if err := json.NewDecoder(w.Body).Decode(&signed); err != nil {
+		t.Fatalf("failed to decode index: %v", err)
+	}
+	return &signed
+}
+
+// TestHandleIndex verifies the index signature and that it tracks catalog changes
+func TestHandleIndex(t *testing.T) {
+	pm := newTestPackageManager(t)
+	keyManager := newTestKeyManager(t)
+	publicKey, err := keyManager.PublicKeyCrypto()
+	if err != nil {
+		t.Fatalf("failed to get public key: %v", err)
+	}
+
+	released := newTestPackageInfo(t, pm.GetStorageDir(), "released-package", "1.0.0")
+	released.State = StateReleased
+	released.Platform = "linux"
+	released.Arch = "amd64"
+	pending := newTestPackageInfo(t, pm.GetStorageDir(), "pending-package", "1.0.0")
+	pending.State = StatePending
+	for _, info := range []*PackageInfo{released, pending} {
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", info.Name, err)
+		}
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		keyManager:     keyManager,
+		packageManager: pm,
+	}
+
+	first := fetchIndex(t, d)
+	index, err := VerifyIndex(first, publicKey)
+	if err != nil {
+		t.Fatalf("index verification failed: %v", err)
+	}
+	if len(index.Packages) != 1 || index.Packages[0].PackageID != released.PackageID {
+		t.Fatalf("expected only the released package in the index, got %+v", index.Packages)
+	}
+	if index.Packages[0].Platform != "linux" || index.Packages[0].Arch != "amd64" {
+		t.Errorf("expected target linux/amd64 in the index, got %s/%s", index.Packages[0].Platform, index.Packages[0].Arch)
+	}
+	if index.SeederID != keyManager.Fingerprint() {
+		t.Errorf("expected seeder_id %s, got %s", keyManager.Fingerprint(), index.SeederID)
+	}
+
+	// Adding a package changes the index and its signature
+	added := newTestPackageInfo(t, pm.GetStorageDir(), "added-package", "2.0.0")
+	added.State = StateReleased
+	if err := pm.AddPackage(added); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	second := fetchIndex(t, d)
+	if second.Signature == first.Signature {
+		t.Error("expected signature to change after adding a package")
+	}
+	index, err = VerifyIndex(second, publicKey)
+	if err != nil {
+		t.Fatalf("index verification failed after add: %v", err)
+	}
+	if len(index.Packages) != 2 {
+		t.Errorf("expected 2 packages in the index, got %d", len(index.Packages))
+	}
+}
+
+// TestVerifyIndex_Tampered verifies a modified index fails verification
+func TestVerifyIndex_Tampered(t *testing.T) {
+	pm := newTestPackageManager(t)
+	keyManager := newTestKeyManager(t)
+	publicKey, _ := keyManager.PublicKeyCrypto()
+
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	info.State = StateReleased
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	d := &Daemon{
+		config:         &DaemonConfig{ListenAddr: "127.0.0.1:0", EnableDHT: false},
+		keyManager:     keyManager,
+		packageManager: pm,
+	}
+
+	signed := fetchIndex(t, d)
+
+	var index PackageIndex
+	if err := json.Unmarshal(signed.Index, &index); err != nil {
+		t.Fatalf("failed to parse index: %v", err)
+	}
+	index.Packages[0].Version = "9.9.9"
+	tampered, _ := json.Marshal(index)
+
+	if _, err := VerifyIndex(&SignedIndex{Index: tampered, Signature: signed.Signature}, publicKey); err == nil {
+		t.Error("expected verification to fail for tampered index")
+	}
+
+	otherKey, _ := newTestKeyManager(t).PublicKeyCrypto()
+	if _, err := VerifyIndex(signed, otherKey); err == nil {
+		t.Error("expected verification to fail against a different key")
+	}
+}
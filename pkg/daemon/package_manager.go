@@ -2,16 +2,50 @@ package daemon
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/libreseed/libreseed/pkg/crypto"
+	packagetypes "github.com/libreseed/libreseed/pkg/package"
 	"github.com/libreseed/libreseed/pkg/storage"
 	"gopkg.in/yaml.v3"
 )
 
+// PackageState represents the release lifecycle state of a package.
+type PackageState string
+
+const (
+	// StatePending indicates the package is stored but not yet released
+	StatePending PackageState = "pending"
+
+	// StateReleased indicates the package is released and may be announced
+	StateReleased PackageState = "released"
+
+	// StateYanked indicates the package was withdrawn and must not be announced
+	StateYanked PackageState = "yanked"
+)
+
+// defaultPackageState is assumed for records without an explicit state.
+// Every package is accepted with verified dual signatures, and records
+// stored before release states existed were all announced, so they are
+// treated as released both when added and when loaded.
+const defaultPackageState = StateReleased
+
+// IsValid reports whether the state is one of the known package states.
+func (s PackageState) IsValid() bool {
+	switch s {
+	case StatePending, StateReleased, StateYanked:
+		return true
+	}
+	return false
+}
+
 // PackageInfo represents metadata about a single package managed by the daemon.
 // This is stored in packages.yaml and tracks local package state.
 type PackageInfo struct {
@@ -36,6 +70,12 @@ type PackageInfo struct {
 	// FileSize is the size of the package file in bytes
 	FileSize int64 `yaml:"file_size"`
 
+	// Platform is the target operating system from the signed manifest (empty = any)
+	Platform string `yaml:"platform,omitempty"`
+
+	// Arch is the target CPU architecture from the signed manifest (empty = any)
+	Arch string `yaml:"arch,omitempty"`
+
 	// CreatedAt is when this package was added to the daemon
 	CreatedAt time.Time `yaml:"created_at"`
 
@@ -51,6 +91,18 @@ type PackageInfo struct {
 	// MaintainerManifestSignature is the hex-encoded maintainer signature
 	MaintainerManifestSignature string `yaml:"maintainer_manifest_signature"`
 
+	// State is the release state (pending, released, yanked).
+	// Only released packages are announced to the DHT.
+	State PackageState `yaml:"state"`
+
+	// Verified caches the result of the last signature verification of the
+	// stored file. It is cleared when the file's modification time moves past
+	// LastVerifiedAt, checked on load and by the daemon's periodic tasks.
+	Verified bool `yaml:"verified"`
+
+	// LastVerifiedAt is the last time the stored file was verified
+	LastVerifiedAt time.Time `yaml:"last_verified_at,omitempty"`
+
 	// AnnouncedToDHT indicates if this package has been announced to the DHT
 	AnnouncedToDHT bool `yaml:"announced_to_dht"`
 
@@ -70,6 +122,14 @@ type PackageManager struct {
 	// metaFile is the path to packages.yaml
 	metaFile string
 
+	// isAllowedFile reports whether a stored filename has an accepted
+	// extension; used when rebuilding the database from storageDir
+	isAllowedFile func(filename string) bool
+
+	// verifyContentHash requires rebuilt packages to pass content hash
+	// verification, as the add handler does when enabled
+	verifyContentHash bool
+
 	// mu protects concurrent access to the packages map
 	mu sync.RWMutex
 }
@@ -83,17 +143,35 @@ type PackageManager struct {
 // Returns a new PackageManager instance ready to use.
 func NewPackageManager(storageDir, metaFile string) *PackageManager {
 	return &PackageManager{
-		packages:   make(map[string]*PackageInfo),
-		storageDir: storageDir,
-		metaFile:   metaFile,
+		packages:      make(map[string]*PackageInfo),
+		storageDir:    storageDir,
+		metaFile:      metaFile,
+		isAllowedFile: (&DaemonConfig{}).IsAllowedExtension,
 	}
 }
 
+// SetAcceptanceRules applies the upload acceptance rules of config (extension
+// allowlist and content hash verification) to packages restored from disk
+// when the metadata file is corrupt. Without it the default extensions are
+// accepted and content hashes are not checked. Call before LoadState.
+//
+// Parameters:
+//   - config: daemon configuration providing the acceptance rules
+func (pm *PackageManager) SetAcceptanceRules(config *DaemonConfig) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.isAllowedFile = config.IsAllowedExtension
+	pm.verifyContentHash = config.VerifyContentHash
+}
+
 // LoadState loads package metadata from packages.yaml.
 // If the file doesn't exist, it initializes an empty state.
+// If the file is corrupt, it is moved aside and the state is rebuilt
+// from the package files in the storage directory (see recoverState).
 // This should be called during daemon startup.
 //
-// Returns error if the file exists but cannot be parsed.
+// Returns error if the file cannot be read or recovery fails.
 func (pm *PackageManager) LoadState() error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
@@ -113,18 +191,137 @@ func (pm *PackageManager) LoadState() error {
 	// Parse YAML into slice of packages
 	var packageList []*PackageInfo
 	if err := yaml.Unmarshal(data, &packageList); err != nil {
-		return fmt.Errorf("failed to parse packages metadata: %w", err)
+		return pm.recoverState(fmt.Errorf("failed to parse packages metadata: %w", err))
+	}
+	for i, pkg := range packageList {
+		if pkg == nil || pkg.PackageID == "" {
+			return pm.recoverState(fmt.Errorf("packages metadata entry %d has no package_id", i))
+		}
 	}
 
 	// Build map from slice
 	pm.packages = make(map[string]*PackageInfo)
 	for _, pkg := range packageList {
+		if pkg.State == "" {
+			pkg.State = defaultPackageState
+		}
+		// A file modified (or lost) since its last verification can no
+		// longer be trusted until it is verified again
+		if pkg.Verified && fileChangedSince(pkg.FilePath, pkg.LastVerifiedAt) {
+			pkg.Verified = false
+		}
 		pm.packages[pkg.PackageID] = pkg
 	}
 
 	return nil
 }
 
+// recoverState handles a corrupt packages.yaml: the file is renamed to
+// <metaFile>.corrupt-<timestamp> so it can be inspected, and the database is
+// rebuilt from the package files in the storage directory. Only files that pass
+// the add handler's checks (allowed extension, valid dual signatures and, when
+// enabled, content hash) are restored, marked verified. A package keeps the
+// release state recorded in the corrupt file if that file still parses;
+// otherwise it is restored as pending and must be promoted again, so a yanked
+// package is never silently re-released. Announcement history is not recovered.
+//
+// Must be called with pm.mu held.
+//
+// Returns error if the corrupt file cannot be moved aside, the storage
+// directory cannot be read, or the rebuilt state cannot be saved.
+func (pm *PackageManager) recoverState(cause error) error {
+	log.Printf("Warning: %v; rebuilding package database from %s\n", cause, pm.storageDir)
+
+	backupPath := fmt.Sprintf("%s.corrupt-%s", pm.metaFile, time.Now().Format("20060102-150405"))
+	if err := os.Rename(pm.metaFile, backupPath); err != nil {
+		return fmt.Errorf("%v; failed to back up corrupt metadata: %w", cause, err)
+	}
+	log.Printf("Corrupt packages metadata moved to %s\n", backupPath)
+
+	previousStates := readPackageStates(backupPath)
+
+	entries, err := os.ReadDir(pm.storageDir)
+	if err != nil {
+		return fmt.Errorf("%v; failed to scan storage directory: %w", cause, err)
+	}
+
+	pm.packages = make(map[string]*PackageInfo)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		filePath := filepath.Join(pm.storageDir, entry.Name())
+		if !pm.isAllowedFile(entry.Name()) {
+			log.Printf("Skipping %s during rebuild: unsupported file type\n", filePath)
+			continue
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Printf("Skipping %s during rebuild: %v\n", filePath, err)
+			continue
+		}
+		pkg, err := packagetypes.LoadPackageFromBytes(data)
+		if err != nil {
+			log.Printf("Skipping %s during rebuild: %v\n", filePath, err)
+			continue
+		}
+		if err := verifyPackageSignatures(pkg); err != nil {
+			log.Printf("Skipping %s during rebuild: %v\n", filePath, err)
+			continue
+		}
+		if pm.verifyContentHash {
+			if err := pkg.Manifest.VerifyContentHash(); err != nil {
+				log.Printf("Skipping %s during rebuild: %v\n", filePath, err)
+				continue
+			}
+		}
+
+		info := newPackageInfo(pkg, filePath)
+		if fileInfo, err := entry.Info(); err == nil {
+			info.CreatedAt = fileInfo.ModTime()
+		}
+		info.State = StatePending
+		if state, ok := previousStates[info.PackageID]; ok {
+			info.State = state
+		}
+		info.Verified = true
+		info.LastVerifiedAt = time.Now()
+		pm.packages[info.PackageID] = info
+	}
+
+	log.Printf("Rebuilt package database with %d package(s)\n", len(pm.packages))
+
+	pm.mu.Unlock()
+	err = pm.SaveState()
+	pm.mu.Lock()
+
+	return err
+}
+
+// readPackageStates extracts the release state of each package recorded in a
+// metadata file, skipping entries without a package ID or a valid state.
+// Returns an empty map if the file cannot be read or parsed.
+func readPackageStates(metaFile string) map[string]PackageState {
+	states := make(map[string]PackageState)
+
+	data, err := os.ReadFile(metaFile)
+	if err != nil {
+		return states
+	}
+	var packageList []*PackageInfo
+	if err := yaml.Unmarshal(data, &packageList); err != nil {
+		return states
+	}
+
+	for _, pkg := range packageList {
+		if pkg != nil && pkg.PackageID != "" && pkg.State.IsValid() {
+			states[pkg.PackageID] = pkg.State
+		}
+	}
+	return states
+}
+
 // SaveState saves the current package metadata to packages.yaml atomically.
 // This should be called after any modification to the package database.
 //
@@ -155,6 +352,7 @@ func (pm *PackageManager) SaveState() error {
 
 // AddPackage adds a new package to the database and persists the change.
 // If a package with the same ID already exists, it returns an error.
+// Packages without an explicit State are stored as released.
 //
 // Parameters:
 //   - info: complete package metadata
@@ -173,6 +371,10 @@ func (pm *PackageManager) AddPackage(info *PackageInfo) error {
 		return fmt.Errorf("package with ID %s already exists", info.PackageID)
 	}
 
+	if info.State == "" {
+		info.State = defaultPackageState
+	}
+
 	// Add to map
 	pm.packages[info.PackageID] = info
 
@@ -185,6 +387,7 @@ func (pm *PackageManager) AddPackage(info *PackageInfo) error {
 }
 
 // RemovePackage removes a package from the database and deletes the package file.
+// The file is kept if another package record still points at it.
 // This operation is permanent and cannot be undone.
 //
 // Parameters:
@@ -201,9 +404,11 @@ func (pm *PackageManager) RemovePackage(packageID string) error {
 		return fmt.Errorf("package with ID %s not found", packageID)
 	}
 
-	// Delete the package file
-	if err := storage.SafeRemove(pkg.FilePath); err != nil {
-		return fmt.Errorf("failed to delete package file: %w", err)
+	// Delete the package file, unless another package record shares it
+	if !pm.filePathInUse(pkg.FilePath, packageID) {
+		if err := storage.SafeRemove(pkg.FilePath); err != nil {
+			return fmt.Errorf("failed to delete package file: %w", err)
+		}
 	}
 
 	// Remove from map
@@ -248,6 +453,64 @@ func (pm *PackageManager) ListPackages() []*PackageInfo {
 	return packageList
 }
 
+// InvalidateChanged clears the cached Verified flag of packages whose file
+// was modified, or can no longer be read, after their last verification.
+// Files are checked without holding the lock; the daemon calls this from its
+// periodic tasks so the read path never touches the filesystem.
+// packages.yaml is only written if a record changed.
+//
+// Returns error if save fails.
+func (pm *PackageManager) InvalidateChanged() error {
+	type target struct {
+		id, filePath string
+		verifiedAt   time.Time
+	}
+
+	pm.mu.RLock()
+	targets := make([]target, 0, len(pm.packages))
+	for id, pkg := range pm.packages {
+		if pkg.Verified {
+			targets = append(targets, target{id, pkg.FilePath, pkg.LastVerifiedAt})
+		}
+	}
+	pm.mu.RUnlock()
+
+	stale := make([]target, 0)
+	for _, t := range targets {
+		if fileChangedSince(t.filePath, t.verifiedAt) {
+			stale = append(stale, t)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	changed := false
+	for _, t := range stale {
+		// Packages removed or verified again meanwhile are skipped
+		pkg, exists := pm.packages[t.id]
+		if !exists || !pkg.Verified || !pkg.LastVerifiedAt.Equal(t.verifiedAt) {
+			continue
+		}
+		log.Printf("Package %s changed on disk since its last verification\n", t.id)
+		pkg.Verified = false
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	return err
+}
+
 // PackageExists checks if a package with the given ID exists.
 //
 // Parameters:
@@ -290,6 +553,288 @@ func (pm *PackageManager) UpdateAnnouncementStatus(packageID string, announced b
 	return err
 }
 
+// SyncAnnouncementStatus applies the announcer's view of several packages at
+// once. A zero time marks a package as not announced; any other time marks it
+// announced at that time. Unknown packages are ignored, and packages.yaml is
+// only written if a record changed.
+//
+// Parameters:
+//   - lastAnnounced: package ID -> time of the last successful announce
+//
+// Returns error if save fails.
+func (pm *PackageManager) SyncAnnouncementStatus(lastAnnounced map[string]time.Time) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	changed := false
+	for packageID, at := range lastAnnounced {
+		pkg, exists := pm.packages[packageID]
+		if !exists {
+			continue
+		}
+
+		announced := !at.IsZero()
+		if pkg.AnnouncedToDHT == announced && (!announced || !at.After(pkg.LastAnnounced)) {
+			continue
+		}
+		pkg.AnnouncedToDHT = announced
+		if announced {
+			pkg.LastAnnounced = at
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	return err
+}
+
+// Touch refreshes the announcement timestamp of a package without changing
+// its content, marking it as freshly announced to the DHT.
+//
+// Parameters:
+//   - packageID: the package ID to touch
+//
+// Returns error if the package doesn't exist or save fails.
+func (pm *PackageManager) Touch(packageID string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pkg, exists := pm.packages[packageID]
+	if !exists {
+		return fmt.Errorf("package with ID %s not found", packageID)
+	}
+
+	pkg.AnnouncedToDHT = true
+	pkg.LastAnnounced = time.Now()
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	return err
+}
+
+// SetPackageState updates the release state of a package.
+//
+// Parameters:
+//   - packageID: the package ID to update
+//   - state: the new release state
+//
+// Returns error if the state is invalid, the package doesn't exist, or save fails.
+func (pm *PackageManager) SetPackageState(packageID string, state PackageState) error {
+	if !state.IsValid() {
+		return fmt.Errorf("invalid package state %q", state)
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pkg, exists := pm.packages[packageID]
+	if !exists {
+		return fmt.Errorf("package with ID %s not found", packageID)
+	}
+
+	pkg.State = state
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	return err
+}
+
+// VerifyPackage re-reads the stored file of a package and checks that it
+// still carries the expected package ID, content hash, and valid dual
+// signatures. The cached Verified flag and LastVerifiedAt are updated
+// with the outcome.
+//
+// Parameters:
+//   - packageID: the package ID to verify
+//
+// Returns whether the package verified, or error if the package doesn't
+// exist or save fails.
+func (pm *PackageManager) VerifyPackage(packageID string) (bool, error) {
+	pm.mu.RLock()
+	pkg, exists := pm.packages[packageID]
+	if !exists {
+		pm.mu.RUnlock()
+		return false, fmt.Errorf("package with ID %s not found", packageID)
+	}
+	filePath, fileHash := pkg.FilePath, pkg.FileHash
+	pm.mu.RUnlock()
+
+	// Verify outside the lock; reading and checking the file may be slow
+	verifyErr := verifyStoredPackage(filePath, packageID, fileHash)
+	if verifyErr != nil {
+		log.Printf("Package %s failed verification: %v\n", packageID, verifyErr)
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pkg, exists = pm.packages[packageID]
+	if !exists {
+		return false, fmt.Errorf("package with ID %s not found", packageID)
+	}
+
+	pkg.Verified = verifyErr == nil
+	pkg.LastVerifiedAt = time.Now()
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	return verifyErr == nil, err
+}
+
+// Scrub verifies every stored package file, updating the cached Verified
+// flag of each package, and persists the results once.
+//
+// Returns the number of packages checked and the IDs of packages that failed
+// verification, or error if save fails.
+func (pm *PackageManager) Scrub() (int, []string, error) {
+	type target struct {
+		id, filePath, fileHash string
+	}
+
+	pm.mu.RLock()
+	targets := make([]target, 0, len(pm.packages))
+	for id, pkg := range pm.packages {
+		targets = append(targets, target{id, pkg.FilePath, pkg.FileHash})
+	}
+	pm.mu.RUnlock()
+
+	results := make(map[string]bool, len(targets))
+	failed := make([]string, 0)
+	for _, t := range targets {
+		if err := verifyStoredPackage(t.filePath, t.id, t.fileHash); err != nil {
+			log.Printf("Package %s failed verification: %v\n", t.id, err)
+			results[t.id] = false
+			failed = append(failed, t.id)
+			continue
+		}
+		results[t.id] = true
+	}
+	sort.Strings(failed)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	now := time.Now()
+	for id, verified := range results {
+		// Packages removed while scrubbing are skipped
+		if pkg, exists := pm.packages[id]; exists {
+			pkg.Verified = verified
+			pkg.LastVerifiedAt = now
+		}
+	}
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	return len(targets), failed, err
+}
+
+// ErrVersionEvicted is returned by PruneVersions when the package being
+// protected is itself among the oldest versions and would be evicted.
+var ErrVersionEvicted = errors.New("version is older than every retained version")
+
+// PruneVersions evicts the oldest versions of a package name so that at most
+// keep versions remain. Versions are ordered by semantic version; evicted
+// packages are removed from the database and their files deleted, except
+// files still referenced by a retained package.
+//
+// The eviction set is computed before anything is deleted. If it contains
+// the package added (the upload that triggered the prune), nothing is
+// removed and ErrVersionEvicted is returned. If a file cannot be deleted,
+// the packages removed so far are still persisted so that the database and
+// packages.yaml agree.
+//
+// Parameters:
+//   - name: the package name to prune
+//   - keep: number of most recent versions to retain (0 = unlimited)
+//   - added: package ID that must not be evicted ("" = none)
+//
+// Returns the evicted packages, which are set even if a removal or save
+// fails, and the error.
+func (pm *PackageManager) PruneVersions(name string, keep int, added string) ([]*PackageInfo, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	var versions []*PackageInfo
+	for _, pkg := range pm.packages {
+		if pkg.Name == name {
+			versions = append(versions, pkg)
+		}
+	}
+
+	if len(versions) <= keep {
+		return nil, nil
+	}
+
+	// Newest first; ties broken by add time so the most recent upload wins
+	sort.Slice(versions, func(i, j int) bool {
+		if c := packagetypes.CompareVersions(versions[i].Version, versions[j].Version); c != 0 {
+			return c > 0
+		}
+		return versions[i].CreatedAt.After(versions[j].CreatedAt)
+	})
+
+	toEvict := versions[keep:]
+	for _, pkg := range toEvict {
+		if added != "" && pkg.PackageID == added {
+			return nil, ErrVersionEvicted
+		}
+	}
+
+	evicted := make([]*PackageInfo, 0, len(toEvict))
+	var removeErr error
+	for _, pkg := range toEvict {
+		// An upload reusing a filename overwrites the older version's file,
+		// so the file may belong to a retained version as well
+		if !pm.filePathInUse(pkg.FilePath, pkg.PackageID) {
+			if err := storage.SafeRemove(pkg.FilePath); err != nil {
+				removeErr = fmt.Errorf("failed to delete package file: %w", err)
+				break
+			}
+		}
+		delete(pm.packages, pkg.PackageID)
+		evicted = append(evicted, pkg)
+	}
+
+	pm.mu.Unlock()
+	err := pm.SaveState()
+	pm.mu.Lock()
+
+	if removeErr != nil {
+		return evicted, removeErr
+	}
+	return evicted, err
+}
+
+// filePathInUse reports whether a package other than exclude is stored at
+// filePath. Must be called with pm.mu held.
+func (pm *PackageManager) filePathInUse(filePath, exclude string) bool {
+	for id, pkg := range pm.packages {
+		if id != exclude && pkg.FilePath == filePath {
+			return true
+		}
+	}
+	return false
+}
+
 // GetStorageDir returns the package storage directory path.
 func (pm *PackageManager) GetStorageDir() string {
 	return pm.storageDir
@@ -366,6 +911,10 @@ func (pm *PackageManager) validatePackageInfo(info *PackageInfo) error {
 		return fmt.Errorf("created_at timestamp is required")
 	}
 
+	if info.State != "" && !info.State.IsValid() {
+		return fmt.Errorf("state must be one of: pending, released, yanked")
+	}
+
 	if info.CreatorFingerprint == "" {
 		return fmt.Errorf("creator_fingerprint is required")
 	}
@@ -412,3 +961,71 @@ func (pm *PackageManager) validatePackageInfo(info *PackageInfo) error {
 
 	return nil
 }
+
+// fileChangedSince reports whether a package file was modified, or can no
+// longer be read, after the given verification time.
+func fileChangedSince(filePath string, verifiedAt time.Time) bool {
+	fileInfo, err := os.Stat(filePath)
+	return err != nil || fileInfo.ModTime().After(verifiedAt)
+}
+
+// verifyStoredPackage checks that the package file at filePath parses, declares
+// the expected package ID and content hash, and carries valid dual signatures.
+func verifyStoredPackage(filePath, packageID, fileHash string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read package file: %w", err)
+	}
+
+	pkg, err := packagetypes.LoadPackageFromBytes(data)
+	if err != nil {
+		return err
+	}
+
+	if pkg.PackageID != packageID {
+		return fmt.Errorf("package ID mismatch: file declares %s", pkg.PackageID)
+	}
+	if pkg.Manifest.ContentHash != fileHash {
+		return fmt.Errorf("content hash mismatch: file declares %s", pkg.Manifest.ContentHash)
+	}
+
+	return verifyPackageSignatures(pkg)
+}
+
+// verifyPackageSignatures checks the creator and maintainer signatures of a parsed package.
+func verifyPackageSignatures(pkg *packagetypes.Package) error {
+	manifestData, err := packagetypes.SerializeManifest(&pkg.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+
+	return crypto.VerifyDualSignature(
+		manifestData,
+		pkg.Manifest.CreatorPubKey,
+		&pkg.ManifestSignature,
+		pkg.Manifest.MaintainerPubKey,
+		&pkg.MaintainerManifestSignature,
+	)
+}
+
+// newPackageInfo builds the database record for a parsed package stored at
+// filePath. CreatedAt is set to now; State, Verified and announcement fields
+// are left for the caller to set.
+func newPackageInfo(pkg *packagetypes.Package, filePath string) *PackageInfo {
+	return &PackageInfo{
+		PackageID:                   pkg.PackageID,
+		Name:                        pkg.Manifest.PackageName,
+		Version:                     pkg.Manifest.Version,
+		Description:                 pkg.Manifest.Description,
+		Platform:                    pkg.Manifest.Platform,
+		Arch:                        pkg.Manifest.Arch,
+		FilePath:                    filePath,
+		FileHash:                    pkg.Manifest.ContentHash,
+		FileSize:                    pkg.SizeBytes,
+		CreatedAt:                   time.Now(),
+		CreatorFingerprint:          pkg.Manifest.CreatorPubKey.Fingerprint(),
+		ManifestSignature:           hex.EncodeToString(pkg.ManifestSignature.SignedData),
+		MaintainerFingerprint:       pkg.Manifest.MaintainerPubKey.Fingerprint(),
+		MaintainerManifestSignature: hex.EncodeToString(pkg.MaintainerManifestSignature.SignedData),
+	}
+}
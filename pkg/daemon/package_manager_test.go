@@ -0,0 +1,541 @@
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	packagetypes "github.com/libreseed/libreseed/pkg/package"
+)
+
+// newTestPackageInfo writes a placeholder package file and returns valid metadata for it
+func newTestPackageInfo(t *testing.T, storageDir, name, version string) *PackageInfo {
+	t.Helper()
+
+	content := []byte(fmt.Sprintf("package %s@%s", name, version))
+	hash := sha256.Sum256(content)
+	packageID := hex.EncodeToString(hash[:])
+
+	filePath := filepath.Join(storageDir, packageID+".lspkg")
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		t.Fatalf("failed to write package file: %v", err)
+	}
+
+	return &PackageInfo{
+		PackageID:                   packageID,
+		Name:                        name,
+		Version:                     version,
+		Description:                 "Test package",
+		FilePath:                    filePath,
+		FileHash:                    packageID,
+		FileSize:                    int64(len(content)),
+		CreatedAt:                   time.Now(),
+		CreatorFingerprint:          "0123456789abcdef",
+		ManifestSignature:           strings.Repeat("ab", 64),
+		MaintainerFingerprint:       "fedcba9876543210",
+		MaintainerManifestSignature: strings.Repeat("cd", 64),
+	}
+}
+
+// newTestPackageManager creates a PackageManager backed by a temporary directory
+func newTestPackageManager(t *testing.T) *PackageManager {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	packagesDir := filepath.Join(tempDir, "packages")
+	if err := os.MkdirAll(packagesDir, 0755); err != nil {
+		t.Fatalf("failed to create packages directory: %v", err)
+	}
+
+	return NewPackageManager(packagesDir, filepath.Join(tempDir, "packages.yaml"))
+}
+
+// TestAddPackage_DefaultsToReleased verifies packages without a state are stored
+// as released, the same default LoadState applies to legacy records
+func TestAddPackage_DefaultsToReleased(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	stored, _ := pm.GetPackage(info.PackageID)
+	if stored.State != StateReleased {
+		t.Errorf("expected state %q, got %q", StateReleased, stored.State)
+	}
+
+	// The state is unchanged across a restart
+	reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+	if err := reloaded.LoadState(); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if stored, _ := reloaded.GetPackage(info.PackageID); stored.State != StateReleased {
+		t.Errorf("expected reloaded state %q, got %q", StateReleased, stored.State)
+	}
+}
+
+// TestSetPackageState tests state transitions and their persistence
+func TestSetPackageState(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	if err := pm.SetPackageState(info.PackageID, StateReleased); err != nil {
+		t.Fatalf("failed to release package: %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+	if err := reloaded.LoadState(); err != nil {
+		t.Fatalf("failed to reload state: %v", err)
+	}
+	stored, _ := reloaded.GetPackage(info.PackageID)
+	if stored.State != StateReleased {
+		t.Errorf("expected state %q after reload, got %q", StateReleased, stored.State)
+	}
+
+	if err := pm.SetPackageState(info.PackageID, "bogus"); err == nil {
+		t.Error("expected error for invalid state")
+	}
+	if err := pm.SetPackageState(strings.Repeat("0", 64), StateYanked); err == nil {
+		t.Error("expected error for unknown package")
+	}
+}
+
+// TestLoadState_LegacyPackagesReleased verifies records without a state load as released
+func TestLoadState_LegacyPackagesReleased(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+
+	legacy := fmt.Sprintf(`- package_id: %s
+  name: %s
+  version: %s
+  description: legacy
+  file_path: %s
+  file_hash: %s
+  file_size: %d
+  created_at: 2025-12-01T10:00:00Z
+  creator_fingerprint: %s
+  manifest_signature: %s
+  maintainer_fingerprint: %s
+  maintainer_manifest_signature: %s
+  announced_to_dht: true
+`, info.PackageID, info.Name, info.Version, info.FilePath, info.FileHash, info.FileSize,
+		info.CreatorFingerprint, info.ManifestSignature, info.MaintainerFingerprint, info.MaintainerManifestSignature)
+	if err := os.WriteFile(pm.GetMetaFile(), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy metadata: %v", err)
+	}
+
+	if err := pm.LoadState(); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	stored, exists := pm.GetPackage(info.PackageID)
+	if !exists {
+		t.Fatal("legacy package not loaded")
+	}
+	if stored.State != StateReleased {
+		t.Errorf("expected legacy package state %q, got %q", StateReleased, stored.State)
+	}
+}
+
+// TestPruneVersions verifies only the newest versions of a package are kept
+func TestPruneVersions(t *testing.T) {
+	pm := newTestPackageManager(t)
+	const keep = 3
+
+	// Added out of order to make sure eviction follows semver, not insertion
+	versions := []string{"1.2.0", "1.0.0", "1.10.0", "1.3.0-beta", "1.3.0"}
+	ids := make(map[string]*PackageInfo)
+	for _, version := range versions[:keep+1] {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", version)
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", version, err)
+		}
+		ids[version] = info
+	}
+
+	other := newTestPackageInfo(t, pm.GetStorageDir(), "other-package", "0.1.0")
+	if err := pm.AddPackage(other); err != nil {
+		t.Fatalf("failed to add other package: %v", err)
+	}
+
+	evicted, err := pm.PruneVersions("test-package", keep, "")
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+
+	if len(evicted) != 1 || evicted[0].Version != "1.0.0" {
+		t.Fatalf("expected only 1.0.0 to be evicted, got %v", evicted)
+	}
+	if pm.PackageExists(ids["1.0.0"].PackageID) {
+		t.Error("evicted package still in database")
+	}
+	if _, err := os.Stat(ids["1.0.0"].FilePath); !os.IsNotExist(err) {
+		t.Error("evicted package file still on disk")
+	}
+	for _, version := range []string{"1.2.0", "1.10.0", "1.3.0-beta"} {
+		if !pm.PackageExists(ids[version].PackageID) {
+			t.Errorf("expected version %s to be kept", version)
+		}
+	}
+	if !pm.PackageExists(other.PackageID) {
+		t.Error("other package must not be pruned")
+	}
+}
+
+// TestPruneVersions_AddedEvicted verifies nothing is removed when the added
+// package would itself be evicted
+func TestPruneVersions_AddedEvicted(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	var infos []*PackageInfo
+	for _, version := range []string{"3.0.0", "2.0.0", "1.0.0"} {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", version)
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", version, err)
+		}
+		infos = append(infos, info)
+	}
+	added := infos[2]
+
+	// Lowering the limit to 1 would evict both 2.0.0 and the added 1.0.0
+	evicted, err := pm.PruneVersions("test-package", 1, added.PackageID)
+	if !errors.Is(err, ErrVersionEvicted) {
+		t.Fatalf("expected ErrVersionEvicted, got %v", err)
+	}
+	if len(evicted) != 0 {
+		t.Errorf("expected no evictions, got %d", len(evicted))
+	}
+	for _, info := range infos {
+		if !pm.PackageExists(info.PackageID) {
+			t.Errorf("version %s removed from database", info.Version)
+		}
+		if _, err := os.Stat(info.FilePath); err != nil {
+			t.Errorf("version %s file removed: %v", info.Version, err)
+		}
+	}
+}
+
+// TestPruneVersions_SharedFilePath verifies evicting an old version does not
+// delete a file the retained version was uploaded over
+func TestPruneVersions_SharedFilePath(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	oldVersion := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	newVersion := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "2.0.0")
+	newVersion.FilePath = oldVersion.FilePath // Uploaded under the same filename
+	for _, info := range []*PackageInfo{oldVersion, newVersion} {
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", info.Version, err)
+		}
+	}
+
+	evicted, err := pm.PruneVersions("test-package", 1, newVersion.PackageID)
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if len(evicted) != 1 || evicted[0].PackageID != oldVersion.PackageID {
+		t.Fatalf("expected 1.0.0 to be evicted, got %v", evicted)
+	}
+	if _, err := os.Stat(newVersion.FilePath); err != nil {
+		t.Errorf("retained version file removed: %v", err)
+	}
+
+	// Removing the last record using the file deletes it
+	if err := pm.RemovePackage(newVersion.PackageID); err != nil {
+		t.Fatalf("RemovePackage failed: %v", err)
+	}
+	if _, err := os.Stat(newVersion.FilePath); !os.IsNotExist(err) {
+		t.Errorf("expected file to be deleted with its last package, got %v", err)
+	}
+}
+
+// TestRemovePackage_SharedFilePath verifies removing a rejected upload keeps
+// the file another version still points at
+func TestRemovePackage_SharedFilePath(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	kept := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "2.0.0")
+	rejected := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	rejected.FilePath = kept.FilePath
+	for _, info := range []*PackageInfo{kept, rejected} {
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", info.Version, err)
+		}
+	}
+
+	if err := pm.RemovePackage(rejected.PackageID); err != nil {
+		t.Fatalf("RemovePackage failed: %v", err)
+	}
+	if _, err := os.Stat(kept.FilePath); err != nil {
+		t.Errorf("file of remaining version removed: %v", err)
+	}
+}
+
+// TestPruneVersions_Unlimited verifies a zero limit keeps every version
+func TestPruneVersions_Unlimited(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	for _, version := range []string{"1.0.0", "2.0.0", "3.0.0"} {
+		info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", version)
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add %s: %v", version, err)
+		}
+	}
+
+	evicted, err := pm.PruneVersions("test-package", 0, "")
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if len(evicted) != 0 {
+		t.Errorf("expected no evictions, got %d", len(evicted))
+	}
+	if pm.Count() != 3 {
+		t.Errorf("expected 3 packages, got %d", pm.Count())
+	}
+}
+
+// TestTouch verifies touch refreshes the announcement timestamp
+func TestTouch(t *testing.T) {
+	pm := newTestPackageManager(t)
+	info := newTestPackageInfo(t, pm.GetStorageDir(), "test-package", "1.0.0")
+	info.LastAnnounced = time.Now().Add(-time.Hour)
+	if err := pm.AddPackage(info); err != nil {
+		t.Fatalf("failed to add package: %v", err)
+	}
+
+	before := time.Now()
+	if err := pm.Touch(info.PackageID); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	stored, _ := pm.GetPackage(info.PackageID)
+	if stored.LastAnnounced.Before(before) {
+		t.Errorf("expected LastAnnounced refreshed, got %v", stored.LastAnnounced)
+	}
+	if !stored.AnnouncedToDHT {
+		t.Error("expected AnnouncedToDHT=true after touch")
+	}
+
+	if err := pm.Touch(strings.Repeat("0", 64)); err == nil {
+		t.Error("expected error touching unknown package")
+	}
+}
+
+// TestLoadState_InvalidatesModifiedFiles verifies a file changed after its
+// last verification loses its cached Verified flag
+func TestLoadState_InvalidatesModifiedFiles(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	unchanged := newTestPackageInfo(t, pm.GetStorageDir(), "unchanged", "1.0.0")
+	modified := newTestPackageInfo(t, pm.GetStorageDir(), "modified", "1.0.0")
+	for _, info := range []*PackageInfo{unchanged, modified} {
+		info.Verified = true
+		info.LastVerifiedAt = time.Now().Add(time.Minute)
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add package: %v", err)
+		}
+	}
+
+	// Simulate the file being rewritten after its last verification
+	modified.LastVerifiedAt = time.Now().Add(-time.Hour)
+	if err := pm.SaveState(); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+	if err := reloaded.LoadState(); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+
+	if stored, _ := reloaded.GetPackage(unchanged.PackageID); !stored.Verified {
+		t.Error("expected unchanged package to remain verified")
+	}
+	if stored, _ := reloaded.GetPackage(modified.PackageID); stored.Verified {
+		t.Error("expected modified package to lose verified status")
+	}
+}
+
+// TestInvalidateChanged verifies a file changed while the daemon runs loses
+// its verified status, and the change is persisted
+func TestInvalidateChanged(t *testing.T) {
+	pm := newTestPackageManager(t)
+
+	unchanged := newTestPackageInfo(t, pm.GetStorageDir(), "unchanged", "1.0.0")
+	modified := newTestPackageInfo(t, pm.GetStorageDir(), "modified", "1.0.0")
+	for _, info := range []*PackageInfo{unchanged, modified} {
+		info.Verified = true
+		info.LastVerifiedAt = time.Now()
+		if err := pm.AddPackage(info); err != nil {
+			t.Fatalf("failed to add package: %v", err)
+		}
+	}
+
+	// Reads never change the cached status
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(modified.FilePath, future, future); err != nil {
+		t.Fatalf("failed to touch package file: %v", err)
+	}
+	if stored, _ := pm.GetPackage(modified.PackageID); !stored.Verified {
+		t.Fatal("expected GetPackage to leave the cached status alone")
+	}
+
+	if err := pm.InvalidateChanged(); err != nil {
+		t.Fatalf("InvalidateChanged failed: %v", err)
+	}
+
+	if stored, _ := pm.GetPackage(unchanged.PackageID); !stored.Verified {
+		t.Error("expected unchanged package to remain verified")
+	}
+	if stored, _ := pm.GetPackage(modified.PackageID); stored.Verified {
+		t.Error("expected modified package to lose verified status")
+	}
+
+	// Rewind the modification time so only the saved state can mark it
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(modified.FilePath, past, past); err != nil {
+		t.Fatalf("failed to touch package file: %v", err)
+	}
+	reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+	if err := reloaded.LoadState(); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if stored, _ := reloaded.GetPackage(modified.PackageID); stored.Verified {
+		t.Error("expected invalidation to be persisted")
+	}
+}
+
+// TestLoadState_CorruptMetadataRebuilds verifies a corrupt packages.yaml is
+// backed up and the database rebuilt from the package files on disk, with
+// packages whose state cannot be recovered restored as pending
+func TestLoadState_CorruptMetadataRebuilds(t *testing.T) {
+	corruptFiles := map[string]string{
+		"truncated yaml": "- package_id: 0123\n  name: [unterminated",
+		"missing id":     "- name: orphan\n",
+	}
+
+	for name, corrupt := range corruptFiles {
+		t.Run(name, func(t *testing.T) {
+			pm := newTestPackageManager(t)
+
+			expected := make(map[string]string)
+			for _, pkgName := range []string{"alpha", "beta"} {
+				pkgData, pkg := createTestPackageFileWith(t, func(m *packagetypes.Manifest) {
+					m.PackageName = pkgName
+				})
+				if err := os.WriteFile(filepath.Join(pm.GetStorageDir(), pkgName+".lspkg"), pkgData, 0644); err != nil {
+					t.Fatalf("failed to write package file: %v", err)
+				}
+				expected[pkg.PackageID] = pkgName
+			}
+
+			// Files that are not valid packages are skipped
+			if err := os.WriteFile(filepath.Join(pm.GetStorageDir(), "junk.lspkg"), []byte("not a package"), 0644); err != nil {
+				t.Fatalf("failed to write junk file: %v", err)
+			}
+
+			if err := os.WriteFile(pm.GetMetaFile(), []byte(corrupt), 0644); err != nil {
+				t.Fatalf("failed to write corrupt metadata: %v", err)
+			}
+
+			if err := pm.LoadState(); err != nil {
+				t.Fatalf("expected recovery, got error: %v", err)
+			}
+
+			if pm.Count() != len(expected) {
+				t.Fatalf("expected %d rebuilt packages, got %d", len(expected), pm.Count())
+			}
+			for id, pkgName := range expected {
+				stored, exists := pm.GetPackage(id)
+				if !exists {
+					t.Errorf("expected %s to be rebuilt", pkgName)
+					continue
+				}
+				if stored.Name != pkgName || stored.State != StatePending || !stored.Verified {
+					t.Errorf("unexpected rebuilt record for %s: name=%q state=%q verified=%v",
+						pkgName, stored.Name, stored.State, stored.Verified)
+				}
+			}
+
+			// The corrupt file is kept aside for inspection
+			backups, _ := filepath.Glob(pm.GetMetaFile() + ".corrupt-*")
+			if len(backups) != 1 {
+				t.Fatalf("expected one backup of the corrupt metadata, got %v", backups)
+			}
+			if data, _ := os.ReadFile(backups[0]); string(data) != corrupt {
+				t.Errorf("backup content mismatch: got %q", data)
+			}
+
+			// The rebuilt state is persisted
+			reloaded := NewPackageManager(pm.GetStorageDir(), pm.GetMetaFile())
+			if err := reloaded.LoadState(); err != nil {
+				t.Fatalf("failed to reload rebuilt state: %v", err)
+			}
+			if reloaded.Count() != len(expected) {
+				t.Errorf("expected %d packages after reload, got %d", len(expected), reloaded.Count())
+			}
+		})
+	}
+}
+
+// TestLoadState_CorruptMetadataRecoveryRules verifies recovery keeps states
+// still readable from the corrupt file and applies the add handler's checks
+func TestLoadState_CorruptMetadataRecoveryRules(t *testing.T) {
+	pm := newTestPackageManager(t)
+	pm.SetAcceptanceRules(&DaemonConfig{VerifyContentHash: true})
+
+	writePackage := func(filename string, mutate func(*packagetypes.Manifest)) *packagetypes.Package {
+		pkgData, pkg := createTestPackageFileWith(t, mutate)
+		if err := os.WriteFile(filepath.Join(pm.GetStorageDir(), filename), pkgData, 0644); err != nil {
+			t.Fatalf("failed to write package file: %v", err)
+		}
+		return pkg
+	}
+	withContentHash := func(name string) func(*packagetypes.Manifest) {
+		return func(m *packagetypes.Manifest) {
+			m.PackageName = name
+			m.ContentHash = packagetypes.ComputeManifestContentHash(m.ContentList)
+		}
+	}
+
+	yanked := writePackage("yanked.lspkg", withContentHash("yanked"))
+	unknown := writePackage("unknown.lspkg", withContentHash("unknown"))
+	badHash := writePackage("bad-hash.lspkg", func(m *packagetypes.Manifest) { m.PackageName = "bad-hash" })
+	badExt := writePackage("bad-ext.tar", withContentHash("bad-ext"))
+
+	// Parses, but is rejected for the entry without a package ID
+	corrupt := "- package_id: " + yanked.PackageID + "\n  state: yanked\n- name: orphan\n"
+	if err := os.WriteFile(pm.GetMetaFile(), []byte(corrupt), 0644); err != nil {
+		t.Fatalf("failed to write corrupt metadata: %v", err)
+	}
+
+	if err := pm.LoadState(); err != nil {
+		t.Fatalf("expected recovery, got error: %v", err)
+	}
+
+	if stored, exists := pm.GetPackage(yanked.PackageID); !exists || stored.State != StateYanked {
+		t.Errorf("expected yanked package to keep its state, got %+v", stored)
+	}
+	if stored, exists := pm.GetPackage(unknown.PackageID); !exists || stored.State != StatePending {
+		t.Errorf("expected package without recorded state to be pending, got %+v", stored)
+	}
+	if _, exists := pm.GetPackage(badHash.PackageID); exists {
+		t.Error("expected package failing content hash verification to be skipped")
+	}
+	if _, exists := pm.GetPackage(badExt.PackageID); exists {
+		t.Error("expected package with disallowed extension to be skipped")
+	}
+	if pm.Count() != 2 {
+		t.Errorf("expected 2 rebuilt packages, got %d", pm.Count())
+	}
+}
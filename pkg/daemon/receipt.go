@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/libreseed/libreseed/pkg/crypto"
+)
+
+// receiptDomain is the signing domain of receipts, so receipt signatures
+// cannot be confused with signatures over other daemon data.
+const receiptDomain = "libreseed-receipt:v1"
+
+// Receipt is a daemon-signed proof that a package was accepted at a given time.
+// It can be verified later against the public key published at /identity.
+type Receipt struct {
+	// PackageID is the ID of the accepted package
+	PackageID string `json:"package_id"`
+
+	// AcceptedAt is when the daemon accepted the package (UTC)
+	AcceptedAt time.Time `json:"accepted_at"`
+
+	// SeederID is the fingerprint of the daemon's public key
+	SeederID string `json:"seeder_id"`
+
+	// Signature is the hex-encoded Ed25519 signature over the receipt payload
+	Signature string `json:"signature"`
+}
+
+// payload returns the canonical bytes covered by the receipt signature.
+func (r *Receipt) payload() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s",
+		r.PackageID, r.AcceptedAt.UTC().Format(time.RFC3339Nano), r.SeederID))
+}
+
+// NewReceipt creates a receipt for a package signed with the daemon's key.
+//
+// Parameters:
+//   - keyManager: the daemon key manager holding the signing key
+//   - packageID: the accepted package ID
+//   - acceptedAt: when the package was accepted
+//
+// Returns the signed receipt, or error if the daemon keys are not loaded.
+func NewReceipt(keyManager *crypto.KeyManager, packageID string, acceptedAt time.Time) (*Receipt, error) {
+	if keyManager == nil || keyManager.PrivateKey() == nil {
+		return nil, fmt.Errorf("daemon signing key not available")
+	}
+
+	receipt := &Receipt{
+		PackageID:  packageID,
+		AcceptedAt: acceptedAt.UTC(),
+		SeederID:   keyManager.Fingerprint(),
+	}
+	signature, err := keyManager.SignDomain(receiptDomain, receipt.payload())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receipt: %w", err)
+	}
+	receipt.Signature = hex.EncodeToString(signature)
+
+	return receipt, nil
+}
+
+// Verify checks the receipt signature against the daemon's public key.
+//
+// Parameters:
+//   - publicKey: the daemon public key (as published at /identity)
+//
+// Returns error if the seeder ID does not match the key or the signature is invalid.
+func (r *Receipt) Verify(publicKey *crypto.PublicKey) error {
+	if publicKey == nil {
+		return crypto.ErrNilPublicKey
+	}
+
+	if r.SeederID != publicKey.Fingerprint() {
+		return fmt.Errorf("receipt seeder_id %s does not match key fingerprint %s", r.SeederID, publicKey.Fingerprint())
+	}
+
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("receipt signature must be valid hex: %w", err)
+	}
+
+	if !crypto.VerifyDomain(publicKey, receiptDomain, r.payload(), signature) {
+		return crypto.ErrInvalidSignature
+	}
+
+	return nil
+}
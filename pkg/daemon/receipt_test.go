@@ -0,0 +1,230 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libreseed/libreseed/pkg/crypto"
+)
+
+// newTestKeyManager creates a key manager with freshly generated keys
+func newTestKeyManager(t *testing.T) *crypto.KeyManager {
+	t.Helper()
+
+	keyManager, err := crypto.NewKeyManager(filepath.Join(t.TempDir(), "keys"))
+	if err != nil {
+		t.Fatalf("failed to create key manager: %v", err)
+	}
+	if err := keyManager.EnsureKeysExist(); err != nil {
+		t.Fatalf("failed to ensure keys: %v", err)
+	}
+	return keyManager
+}
+
+// TestHandlePackageAdd_Receipt verifies the add receipt against the key published at /identity
+func TestHandlePackageAdd_Receipt(t *testing.T) {
+	tempDir := t.TempDir()
+	packagesDir := filepath.Join(tempDir, "packages")
+	os.MkdirAll(packagesDir, 0755)
+
+	d := &Daemon{
+		config: &DaemonConfig{
+			StorageDir: tempDir,
+			ListenAddr: "127.0.0.1:0",
+			EnableDHT:  false,
+		},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		keyManager:     newTestKeyManager(t),
+		packageManager: NewPackageManager(packagesDir, filepath.Join(tempDir, "packages.yaml")),
+	}
+
+	pkgData, pkg := createTestPackageFile(t)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "test.lspkg")
+	part.Write(pkgData)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/add?receipt=true", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	d.handlePackageAdd(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Receipt *Receipt `json:"receipt"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Receipt == nil {
+		t.Fatal("expected receipt in response")
+	}
+	if response.Receipt.PackageID != pkg.PackageID {
+		t.Errorf("expected receipt package_id %s, got %s", pkg.PackageID, response.Receipt.PackageID)
+	}
+
+	// Fetch the daemon public key from /identity
+	identityReq := httptest.NewRequest(http.MethodGet, "/identity", nil)
+	identityW := httptest.NewRecorder()
+	d.handleIdentity(identityW, identityReq)
+
+	var identity map[string]string
+	if err := json.NewDecoder(identityW.Body).Decode(&identity); err != nil {
+		t.Fatalf("failed to decode identity: %v", err)
+	}
+	keyBytes, err := hex.DecodeString(identity["public_key"])
+	if err != nil {
+		t.Fatalf("invalid public key hex: %v", err)
+	}
+	publicKey, err := crypto.NewPublicKey(keyBytes)
+	if err != nil {
+		t.Fatalf("invalid public key: %v", err)
+	}
+
+	if response.Receipt.SeederID != identity["seeder_id"] {
+		t.Errorf("expected seeder_id %s, got %s", identity["seeder_id"], response.Receipt.SeederID)
+	}
+	if err := response.Receipt.Verify(publicKey); err != nil {
+		t.Errorf("receipt verification failed: %v", err)
+	}
+}
+
+// TestHandlePackageAdd_NoReceiptByDefault verifies receipts are opt-in
+func TestHandlePackageAdd_NoReceiptByDefault(t *testing.T) {
+	tempDir := t.TempDir()
+	packagesDir := filepath.Join(tempDir, "packages")
+	os.MkdirAll(packagesDir, 0755)
+
+	d := &Daemon{
+		config:         &DaemonConfig{StorageDir: tempDir, ListenAddr: "127.0.0.1:0"},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		keyManager:     newTestKeyManager(t),
+		packageManager: NewPackageManager(packagesDir, filepath.Join(tempDir, "packages.yaml")),
+	}
+
+	pkgData, _ := createTestPackageFile(t)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "test.lspkg")
+	part.Write(pkgData)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/add", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	d.handlePackageAdd(w, req)
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := response["receipt"]; ok {
+		t.Error("expected no receipt without receipt=true")
+	}
+}
+
+// TestHandlePackageAdd_ReceiptError verifies a receipt that cannot be signed
+// is reported instead of silently omitted
+func TestHandlePackageAdd_ReceiptError(t *testing.T) {
+	tempDir := t.TempDir()
+	packagesDir := filepath.Join(tempDir, "packages")
+	os.MkdirAll(packagesDir, 0755)
+
+	// No key manager: the daemon cannot sign receipts
+	d := &Daemon{
+		config:         &DaemonConfig{StorageDir: tempDir, ListenAddr: "127.0.0.1:0"},
+		state:          NewDaemonState(),
+		stats:          NewDaemonStatistics(),
+		packageManager: NewPackageManager(packagesDir, filepath.Join(tempDir, "packages.yaml")),
+	}
+
+	pkgData, _ := createTestPackageFile(t)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "test.lspkg")
+	part.Write(pkgData)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/add?receipt=true", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	d.handlePackageAdd(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := response["receipt"]; ok {
+		t.Error("expected no receipt when signing fails")
+	}
+	if msg, _ := response["receipt_error"].(string); msg == "" {
+		t.Errorf("expected receipt_error in response, got %v", response)
+	}
+}
+
+// TestReceiptVerify_Tampered verifies any modified field invalidates the receipt
+func TestReceiptVerify_Tampered(t *testing.T) {
+	keyManager := newTestKeyManager(t)
+	publicKey, err := keyManager.PublicKeyCrypto()
+	if err != nil {
+		t.Fatalf("failed to get public key: %v", err)
+	}
+
+	receipt, err := NewReceipt(keyManager, "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", time.Now())
+	if err != nil {
+		t.Fatalf("NewReceipt failed: %v", err)
+	}
+	if err := receipt.Verify(publicKey); err != nil {
+		t.Fatalf("untampered receipt failed verification: %v", err)
+	}
+
+	tampered := *receipt
+	tampered.PackageID = "f" + receipt.PackageID[1:]
+	if err := tampered.Verify(publicKey); err == nil {
+		t.Error("expected verification to fail for tampered package_id")
+	}
+
+	tampered = *receipt
+	tampered.AcceptedAt = receipt.AcceptedAt.Add(time.Second)
+	if err := tampered.Verify(publicKey); err == nil {
+		t.Error("expected verification to fail for tampered accepted_at")
+	}
+
+	otherKey, err := newTestKeyManager(t).PublicKeyCrypto()
+	if err != nil {
+		t.Fatalf("failed to get other public key: %v", err)
+	}
+	if err := receipt.Verify(otherKey); err == nil {
+		t.Error("expected verification to fail against a different key")
+	}
+
+	// A receipt signature is bound to the receipt domain
+	signature, _ := hex.DecodeString(receipt.Signature)
+	if crypto.VerifyDomain(publicKey, indexDomain, receipt.payload(), signature) {
+		t.Error("expected receipt signature to be invalid in the index domain")
+	}
+}
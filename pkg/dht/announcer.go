@@ -3,7 +3,10 @@ package dht
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,20 +19,31 @@ import (
 type PackageAnnouncement struct {
 	InfoHash              metainfo.Hash
 	PackageName           string
-	CreatorFingerprint    string // Fingerprint of the package creator's public key
-	MaintainerFingerprint string // Fingerprint of the package maintainer's public key
+	CreatorFingerprint    string   // Fingerprint of the package creator's public key
+	MaintainerFingerprint string   // Fingerprint of the package maintainer's public key
+	PackageIDs            []string // Packages sharing this InfoHash (replaced, never modified in place)
 	LastAnnounced         time.Time
+	NextAnnounce          time.Time // Zero until first announced; due immediately
 	AnnounceCount         int
 	Failed                bool
 	LastError             error
 }
 
+// DefaultAnnouncePort is the port advertised in announces unless overridden
+const DefaultAnnouncePort = 6881
+
 // Announcer manages periodic announcements of packages to the DHT
 type Announcer struct {
 	client   DHTClient
 	mu       sync.RWMutex
 	packages map[metainfo.Hash]*PackageAnnouncement
 	interval time.Duration
+	jitter   time.Duration
+	port     int
+	breaker  *CircuitBreaker
+	now      func() time.Time
+	randN    func(n int64) int64
+	wake     chan struct{} // signals the worker that a new package is due
 	ctx      context.Context
 	cancel   context.CancelFunc
 	wg       sync.WaitGroup
@@ -42,11 +56,49 @@ func NewAnnouncer(client DHTClient, interval time.Duration) *Announcer {
 		client:   client,
 		packages: make(map[metainfo.Hash]*PackageAnnouncement),
 		interval: interval,
+		port:     DefaultAnnouncePort,
+		breaker:  NewCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+		now:      time.Now,
+		randN:    rand.Int64N,
+		wake:     make(chan struct{}, 1),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
 }
 
+// SetAnnouncePort sets the port advertised to peers in announces.
+// Should be called before Start.
+func (a *Announcer) SetAnnouncePort(port int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.port = port
+}
+
+// SetJitter sets the random delay window added to each package's republish time,
+// so packages announced together don't all come due at the same instant again.
+// Should be called before Start.
+func (a *Announcer) SetJitter(jitter time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.jitter = jitter
+}
+
+// SetCircuitBreaker configures the announce circuit breaker.
+// A threshold of 0 disables it. Should be called before Start.
+func (a *Announcer) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.breaker = NewCircuitBreaker(threshold, cooldown)
+}
+
+// BreakerStatus returns the state of the announce circuit breaker
+func (a *Announcer) BreakerStatus() BreakerStatus {
+	a.mu.RLock()
+	breaker := a.breaker
+	a.mu.RUnlock()
+	return breaker.Status()
+}
+
 // Start begins the announcement worker
 func (a *Announcer) Start() {
 	log.Printf("=== ANNOUNCER START CALLED ===")
@@ -73,6 +125,17 @@ func (a *Announcer) AddPackage(infoHash metainfo.Hash, packageName string, creat
 			CreatorFingerprint:    creatorFingerprint,
 			MaintainerFingerprint: maintainerFingerprint,
 		}
+		a.notifyWorker()
+	}
+}
+
+// notifyWorker wakes the worker so a newly added package is announced
+// without waiting for the current timer. Never blocks; a pending wake-up
+// already covers every package added since.
+func (a *Announcer) notifyWorker() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
 	}
 }
 
@@ -84,6 +147,66 @@ func (a *Announcer) RemovePackage(infoHash metainfo.Hash) {
 	delete(a.packages, infoHash)
 }
 
+// AddPackageRef registers packageID as a reference to infoHash
+// Packages with the same InfoHash share a single DHT entry, announced once
+// Returns true if a new entry was created, false if one already existed
+func (a *Announcer) AddPackageRef(infoHash metainfo.Hash, packageID string, packageName string, creatorFingerprint string, maintainerFingerprint string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pkg, exists := a.packages[infoHash]
+	if !exists {
+		a.packages[infoHash] = &PackageAnnouncement{
+			InfoHash:              infoHash,
+			PackageName:           packageName,
+			CreatorFingerprint:    creatorFingerprint,
+			MaintainerFingerprint: maintainerFingerprint,
+			PackageIDs:            []string{packageID},
+		}
+		a.notifyWorker()
+		return true
+	}
+
+	if slices.Contains(pkg.PackageIDs, packageID) {
+		return false
+	}
+
+	ids := append(slices.Clone(pkg.PackageIDs), packageID)
+	slices.Sort(ids)
+	pkg.PackageIDs = ids
+	return false
+}
+
+// RemovePackageRef drops packageID's reference to infoHash
+// The entry stops being announced only once no package references it
+// Returns true if the entry was removed
+func (a *Announcer) RemovePackageRef(infoHash metainfo.Hash, packageID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pkg, exists := a.packages[infoHash]
+	if !exists {
+		return false
+	}
+
+	// Entries added with AddPackage carry no references
+	if len(pkg.PackageIDs) > 0 {
+		if !slices.Contains(pkg.PackageIDs, packageID) {
+			return false
+		}
+		ids := slices.DeleteFunc(slices.Clone(pkg.PackageIDs), func(id string) bool {
+			return id == packageID
+		})
+		if len(ids) > 0 {
+			pkg.PackageIDs = ids
+			return false
+		}
+	}
+
+	delete(a.packages, infoHash)
+	return true
+}
+
 // GetPackages returns all tracked packages
 func (a *Announcer) GetPackages() []*PackageAnnouncement {
 	a.mu.RLock()
@@ -114,27 +237,100 @@ func (a *Announcer) GetPackage(infoHash metainfo.Hash) (*PackageAnnouncement, bo
 }
 
 // worker runs the periodic announcement loop
+// Each package is republished when its own NextAnnounce comes due
 func (a *Announcer) worker() {
 	defer a.wg.Done()
 
-	log.Printf("=== ANNOUNCER WORKER STARTED, interval=%v ===", a.interval)
-	ticker := time.NewTicker(a.interval)
-	defer ticker.Stop()
+	log.Printf("=== ANNOUNCER WORKER STARTED, interval=%v, jitter=%v ===", a.interval, a.jitter)
 
 	// Announce immediately on startup
 	log.Printf("=== ANNOUNCER: Initial announceAll() call ===")
 	a.announceAll()
 
 	for {
+		timer := time.NewTimer(a.untilNextDue())
 		select {
 		case <-a.ctx.Done():
+			timer.Stop()
 			log.Printf("=== ANNOUNCER WORKER STOPPED ===")
 			return
-		case <-ticker.C:
-			log.Printf("=== ANNOUNCER: Periodic announceAll() triggered ===")
-			a.announceAll()
+		case <-timer.C:
+			a.announceDue()
+		case <-a.wake:
+			// New packages are due immediately; recompute the wait
+			timer.Stop()
+		}
+	}
+}
+
+// untilNextDue returns how long until the earliest scheduled republish
+func (a *Announcer) untilNextDue() time.Duration {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.packages) == 0 {
+		return a.interval
+	}
+
+	var next time.Time
+	for _, pkg := range a.packages {
+		if next.IsZero() || pkg.NextAnnounce.Before(next) {
+			next = pkg.NextAnnounce
 		}
 	}
+
+	wait := next.Sub(a.now())
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// announceDue announces the packages whose republish time has come
+func (a *Announcer) announceDue() {
+	now := a.now()
+
+	a.mu.RLock()
+	due := make([]metainfo.Hash, 0, len(a.packages))
+	for infoHash, pkg := range a.packages {
+		if !pkg.NextAnnounce.After(now) {
+			due = append(due, infoHash)
+		}
+	}
+	a.mu.RUnlock()
+
+	log.Printf("=== announceDue: %d packages due ===", len(due))
+
+	for _, infoHash := range due {
+		a.announcePackage(infoHash)
+	}
+}
+
+// scheduleNext sets the package's next republish time to one interval
+// from now plus a random delay within the jitter window.
+// Must be called with a.mu held.
+func (a *Announcer) scheduleNext(pkg *PackageAnnouncement) {
+	delay := a.interval
+	if a.jitter > 0 {
+		delay += time.Duration(a.randN(int64(a.jitter)))
+	}
+	pkg.NextAnnounce = a.now().Add(delay)
+}
+
+// scheduleProbe sets a deferred package's next announce to when the breaker
+// half-opens, so it is retried after the cooldown rather than a full interval
+// later. If the cooldown already elapsed, a probe is in flight and the package
+// is retried one cooldown from now.
+// Must be called with a.mu held.
+func (a *Announcer) scheduleProbe(pkg *PackageAnnouncement, breaker *CircuitBreaker) {
+	status := breaker.Status()
+	now := a.now()
+
+	next := status.OpenedAt.Add(status.Cooldown)
+	if !next.After(now) {
+		next = now.Add(status.Cooldown)
+	}
+	pkg.NextAnnounce = next
 }
 
 // announceAll announces all packages to the DHT
@@ -155,10 +351,46 @@ func (a *Announcer) announceAll() {
 	}
 }
 
+// AnnounceNow announces a tracked package immediately, outside its schedule
+// Returns the announce error, or an error if the package isn't tracked
+func (a *Announcer) AnnounceNow(infoHash metainfo.Hash) error {
+	a.mu.RLock()
+	_, exists := a.packages[infoHash]
+	a.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("package %s is not tracked by the announcer", infoHash.HexString())
+	}
+
+	return a.announcePackage(infoHash)
+}
+
 // announcePackage announces a single package to the DHT
-func (a *Announcer) announcePackage(infoHash metainfo.Hash) {
-	log.Printf("=== Calling client.Announce for InfoHash: %s ===", infoHash.HexString())
-	err := a.client.Announce(infoHash, 6881) // Default BitTorrent port
+func (a *Announcer) announcePackage(infoHash metainfo.Hash) error {
+	a.mu.RLock()
+	port := a.port
+	breaker := a.breaker
+	a.mu.RUnlock()
+
+	// Defer the announce while the DHT is failing
+	if !breaker.Allow() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if pkg, exists := a.packages[infoHash]; exists {
+			pkg.Failed = true
+			pkg.LastError = ErrBreakerOpen
+			a.scheduleProbe(pkg, breaker)
+		}
+		return ErrBreakerOpen
+	}
+
+	log.Printf("=== Calling client.Announce for InfoHash: %s (port %d) ===", infoHash.HexString(), port)
+	err := a.client.Announce(infoHash, port)
+	if err != nil {
+		breaker.RecordFailure()
+	} else {
+		breaker.RecordSuccess()
+	}
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -166,11 +398,12 @@ func (a *Announcer) announcePackage(infoHash metainfo.Hash) {
 	pkg, exists := a.packages[infoHash]
 	if !exists {
 		log.Printf("=== ERROR: Package not found in map after announce! ===")
-		return
+		return err
 	}
 
-	pkg.LastAnnounced = time.Now()
+	pkg.LastAnnounced = a.now()
 	pkg.AnnounceCount++
+	a.scheduleNext(pkg)
 
 	if err != nil {
 		log.Printf("=== Announce FAILED: %v ===", err)
@@ -181,6 +414,8 @@ func (a *Announcer) announcePackage(infoHash metainfo.Hash) {
 		pkg.Failed = false
 		pkg.LastError = nil
 	}
+
+	return err
 }
 
 // GetStats returns statistics about announcements
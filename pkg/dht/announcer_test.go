@@ -86,7 +86,14 @@ func (m *mockDHTClient) GetPeers(infoHash [20]byte) ([]net.Addr, error) {
 func (m *mockDHTClient) GetStats() ClientStats {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.stats
+	return ClientStats{
+		NodesInRoutingTable: m.stats.NodesInRoutingTable,
+		TotalQueries:        m.stats.TotalQueries,
+		TotalResponses:      m.stats.TotalResponses,
+		TotalAnnounces:      m.stats.TotalAnnounces,
+		TotalLookups:        m.stats.TotalLookups,
+		LastBootstrap:       m.stats.LastBootstrap,
+	}
 }
 
 func (m *mockDHTClient) NodeID() [20]byte {
@@ -218,6 +225,62 @@ func TestRemovePackage(t *testing.T) {
 	}
 }
 
+// TestAnnouncerPackageRefs verifies packages sharing an InfoHash are announced
+// once and the entry is kept until the last reference is removed
+func TestAnnouncerPackageRefs(t *testing.T) {
+	client := newMockDHTClient()
+	client.Start()
+	announcer := NewAnnouncer(client, time.Hour)
+
+	infoHash := testInfoHash(1)
+
+	if !announcer.AddPackageRef(infoHash, "id-a", "pkg-a", "creator", "maintainer") {
+		t.Error("Expected first reference to create the entry")
+	}
+	if announcer.AddPackageRef(infoHash, "id-b", "pkg-b", "creator", "maintainer") {
+		t.Error("Expected second reference to reuse the entry")
+	}
+	announcer.AddPackageRef(infoHash, "id-a", "pkg-a", "creator", "maintainer") // Duplicate reference is a no-op
+
+	pkg, exists := announcer.GetPackage(infoHash)
+	if !exists {
+		t.Fatal("Expected shared entry to exist")
+	}
+	if len(pkg.PackageIDs) != 2 || pkg.PackageIDs[0] != "id-a" || pkg.PackageIDs[1] != "id-b" {
+		t.Errorf("Expected references [id-a id-b], got %v", pkg.PackageIDs)
+	}
+
+	announcer.announceAll()
+
+	client.mu.RLock()
+	announces := client.announcedHashes[infoHash]
+	client.mu.RUnlock()
+	if announces != 1 {
+		t.Errorf("Expected a single announce for the shared InfoHash, got %d", announces)
+	}
+
+	if announcer.RemovePackageRef(infoHash, "id-unknown") {
+		t.Error("Removing an unknown reference must not remove the entry")
+	}
+	if announcer.RemovePackageRef(infoHash, "id-a") {
+		t.Error("Expected entry to remain while id-b references it")
+	}
+	pkg, exists = announcer.GetPackage(infoHash)
+	if !exists {
+		t.Fatal("Expected entry to remain after removing one reference")
+	}
+	if len(pkg.PackageIDs) != 1 || pkg.PackageIDs[0] != "id-b" {
+		t.Errorf("Expected references [id-b], got %v", pkg.PackageIDs)
+	}
+
+	if !announcer.RemovePackageRef(infoHash, "id-b") {
+		t.Error("Expected removing the last reference to remove the entry")
+	}
+	if _, exists := announcer.GetPackage(infoHash); exists {
+		t.Error("Expected entry to be gone after removing the last reference")
+	}
+}
+
 // TestGetPackage verifies retrieving specific packages
 func TestGetPackage(t *testing.T) {
 	client := newMockDHTClient()
@@ -326,6 +389,40 @@ func TestAnnouncerErrorHandling(t *testing.T) {
 	}
 }
 
+// TestAnnouncerAnnouncePort verifies the configured announce port reaches the DHT client
+func TestAnnouncerAnnouncePort(t *testing.T) {
+	client := newMockDHTClient()
+	client.Start()
+
+	var mu sync.Mutex
+	ports := make([]int, 0)
+	client.announceFunc = func(infoHash [20]byte, port int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ports = append(ports, port)
+		return nil
+	}
+
+	announcer := NewAnnouncer(client, time.Hour)
+	announcer.SetAnnouncePort(40000)
+	announcer.AddPackage(testInfoHash(1), "test-pkg", "creator", "maintainer")
+
+	announcer.Start()
+	time.Sleep(50 * time.Millisecond)
+	announcer.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ports) == 0 {
+		t.Fatal("Package was not announced")
+	}
+	for _, port := range ports {
+		if port != 40000 {
+			t.Errorf("Announce port: got %d, want 40000", port)
+		}
+	}
+}
+
 // TestAnnouncerStats verifies statistics tracking
 func TestAnnouncerStats(t *testing.T) {
 	client := newMockDHTClient()
@@ -413,6 +510,29 @@ func TestAnnouncerImmediateAnnounce(t *testing.T) {
 	}
 }
 
+// TestAnnouncerAnnouncesAddedPackage verifies a package added while the worker
+// sleeps is announced without waiting for the interval
+func TestAnnouncerAnnouncesAddedPackage(t *testing.T) {
+	client := newMockDHTClient()
+	client.Start()
+	announcer := NewAnnouncer(client, time.Hour) // Long interval
+
+	announcer.Start()
+	defer announcer.Stop()
+	time.Sleep(50 * time.Millisecond) // Let the worker go to sleep
+
+	infoHash := testInfoHash(1)
+	announcer.AddPackageRef(infoHash, "pkg-1", "test-pkg", "creator", "maintainer")
+
+	deadline := time.Now().Add(time.Second)
+	for client.getHashAnnounceCount(infoHash) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected added package to be announced without waiting for the interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // TestAnnouncerPackageCount verifies package tracking
 func TestAnnouncerPackageCount(t *testing.T) {
 	client := newMockDHTClient()
@@ -486,3 +606,188 @@ func BenchmarkAnnouncePackage(b *testing.B) {
 		announcer.announcePackage(infoHash)
 	}
 }
+
+// TestAnnouncerCircuitBreaker verifies the breaker opens after repeated failures
+// and closes again after a successful probe
+func TestAnnouncerCircuitBreaker(t *testing.T) {
+	client := newMockDHTClient()
+	client.Start()
+
+	failing := true
+	client.announceFunc = func(infoHash [20]byte, port int) error {
+		if failing {
+			return fmt.Errorf("simulated DHT saturation")
+		}
+		return nil
+	}
+
+	const cooldown = 10 * time.Minute
+	announcer := NewAnnouncer(client, time.Minute)
+	announcer.SetCircuitBreaker(3, cooldown)
+
+	now := time.Now()
+	announcer.now = func() time.Time { return now }
+	announcer.breaker.now = func() time.Time { return now }
+
+	infoHash := testInfoHash(1)
+	announcer.AddPackage(infoHash, "test-pkg", "creator", "maintainer")
+
+	// Each failed announce is retried one interval later
+	for i := 0; i < 3; i++ {
+		announcer.announceDue()
+		now = now.Add(announcer.untilNextDue())
+	}
+	openedAt := now.Add(-time.Minute)
+
+	status := announcer.BreakerStatus()
+	if status.State != BreakerOpen {
+		t.Fatalf("Expected breaker open after 3 failures, got %s", status.State)
+	}
+
+	// Announces are deferred while open, until the breaker half-opens
+	announcer.announceDue()
+	if count := client.getAnnounceCount(); count != 3 {
+		t.Errorf("Expected no DHT call while open, got %d calls", count)
+	}
+	pkg, _ := announcer.GetPackage(infoHash)
+	if !pkg.Failed || pkg.LastError != ErrBreakerOpen {
+		t.Errorf("Expected package marked deferred, got Failed=%v LastError=%v", pkg.Failed, pkg.LastError)
+	}
+	if !pkg.NextAnnounce.Equal(openedAt.Add(cooldown)) {
+		t.Errorf("Expected deferred package rescheduled at half-open %v, got %v", openedAt.Add(cooldown), pkg.NextAnnounce)
+	}
+	if wait := announcer.untilNextDue(); wait != openedAt.Add(cooldown).Sub(now) {
+		t.Errorf("Expected worker to wait until half-open, got %v", wait)
+	}
+
+	// After the cooldown a successful probe closes the breaker
+	failing = false
+	now = now.Add(announcer.untilNextDue())
+	announcer.announceDue()
+
+	if count := client.getAnnounceCount(); count != 4 {
+		t.Errorf("Expected probe announce, got %d calls", count)
+	}
+	status = announcer.BreakerStatus()
+	if status.State != BreakerClosed {
+		t.Errorf("Expected breaker closed after successful probe, got %s", status.State)
+	}
+	if status.ConsecutiveFailures != 0 {
+		t.Errorf("Expected failures reset, got %d", status.ConsecutiveFailures)
+	}
+	pkg, _ = announcer.GetPackage(infoHash)
+	if pkg.Failed {
+		t.Error("Package should be announced after recovery")
+	}
+}
+
+// TestCircuitBreakerHalfOpenFailure verifies a failed probe reopens the breaker
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	breaker.RecordFailure()
+	if breaker.Allow() {
+		t.Fatal("Expected breaker to reject calls while open")
+	}
+
+	now = now.Add(time.Minute)
+	if !breaker.Allow() {
+		t.Fatal("Expected probe to be allowed after cooldown")
+	}
+	if breaker.Allow() {
+		t.Error("Expected only one probe while half-open")
+	}
+
+	breaker.RecordFailure()
+	status := breaker.Status()
+	if status.State != BreakerOpen {
+		t.Errorf("Expected breaker reopened after failed probe, got %s", status.State)
+	}
+	if !status.OpenedAt.Equal(now) {
+		t.Errorf("Expected cooldown restarted at probe failure time")
+	}
+}
+
+// TestCircuitBreakerDisabled verifies a zero threshold never opens
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := NewCircuitBreaker(0, time.Minute)
+	for i := 0; i < 100; i++ {
+		breaker.RecordFailure()
+	}
+	if !breaker.Allow() {
+		t.Error("Disabled breaker should always allow calls")
+	}
+}
+
+// TestAnnouncerJitterSpreadsRepublish verifies packages announced together are
+// rescheduled across the jitter window instead of all at the same instant
+func TestAnnouncerJitterSpreadsRepublish(t *testing.T) {
+	client := newMockDHTClient()
+	client.Start()
+
+	interval := 30 * time.Minute
+	jitter := 5 * time.Minute
+
+	announcer := NewAnnouncer(client, interval)
+	announcer.SetJitter(jitter)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	announcer.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		announcer.AddPackage(testInfoHash(byte(i)), fmt.Sprintf("pkg%d", i), "creator", "maintainer")
+	}
+
+	// Every package is due at once
+	announcer.announceDue()
+
+	earliest := now.Add(interval)
+	latest := now.Add(interval + jitter)
+	distinct := make(map[time.Time]bool)
+	for _, pkg := range announcer.GetPackages() {
+		if pkg.NextAnnounce.Before(earliest) || !pkg.NextAnnounce.Before(latest) {
+			t.Errorf("NextAnnounce %v outside [%v, %v)", pkg.NextAnnounce, earliest, latest)
+		}
+		distinct[pkg.NextAnnounce] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("Expected republish times to be spread across the jitter window")
+	}
+
+	// Nothing is due again until the earliest scheduled time
+	announcer.announceDue()
+	if count := client.getAnnounceCount(); count != 100 {
+		t.Errorf("Expected 100 announces, got %d", count)
+	}
+}
+
+// TestAnnouncerNoJitter verifies republish is exactly one interval later without jitter
+func TestAnnouncerNoJitter(t *testing.T) {
+	client := newMockDHTClient()
+	client.Start()
+
+	announcer := NewAnnouncer(client, time.Hour)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	announcer.now = func() time.Time { return now }
+
+	infoHash := testInfoHash(1)
+	announcer.AddPackage(infoHash, "test-pkg", "creator", "maintainer")
+	announcer.announceDue()
+
+	pkg, _ := announcer.GetPackage(infoHash)
+	if !pkg.NextAnnounce.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected NextAnnounce %v, got %v", now.Add(time.Hour), pkg.NextAnnounce)
+	}
+
+	now = now.Add(time.Hour)
+	if wait := announcer.untilNextDue(); wait != 0 {
+		t.Errorf("Expected package due, got wait %v", wait)
+	}
+	announcer.announceDue()
+	if count := client.getAnnounceCount(); count != 2 {
+		t.Errorf("Expected 2 announces, got %d", count)
+	}
+}
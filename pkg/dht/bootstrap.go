@@ -0,0 +1,89 @@
+// Package dht provides DHT integration for libreseed
+package dht
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBootstrapTimeout is how long to wait for the DHT to bootstrap
+// before giving up and running degraded
+const DefaultBootstrapTimeout = 2 * time.Minute
+
+// BootstrapState is the bootstrap progress of a DHT client
+type BootstrapState string
+
+const (
+	// BootstrapPending means no DHT node has been found yet
+	BootstrapPending BootstrapState = "pending"
+
+	// BootstrapComplete means at least one DHT node is in the routing table
+	BootstrapComplete BootstrapState = "complete"
+
+	// BootstrapTimedOut means the timeout elapsed before any node was found
+	BootstrapTimedOut BootstrapState = "timed-out"
+)
+
+// BootstrapStatus is a snapshot of a bootstrap watcher
+type BootstrapStatus struct {
+	State       BootstrapState
+	Nodes       int
+	StartedAt   time.Time
+	CompletedAt time.Time // Zero until bootstrap completes
+	Timeout     time.Duration
+}
+
+// BootstrapWatcher tracks whether a DHT client has joined the network.
+// Bootstrap completes once the routing table holds at least one node and
+// stays complete from then on. If the timeout elapses first the watcher
+// reports timed-out, but still completes if nodes show up later.
+type BootstrapWatcher struct {
+	mu          sync.Mutex
+	client      DHTClient
+	timeout     time.Duration
+	startedAt   time.Time
+	completedAt time.Time
+	now         func() time.Time
+}
+
+// NewBootstrapWatcher starts watching the bootstrap of client.
+// A timeout of 0 uses DefaultBootstrapTimeout.
+func NewBootstrapWatcher(client DHTClient, timeout time.Duration) *BootstrapWatcher {
+	if timeout <= 0 {
+		timeout = DefaultBootstrapTimeout
+	}
+	return &BootstrapWatcher{
+		client:    client,
+		timeout:   timeout,
+		startedAt: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Status checks the client's routing table and returns the bootstrap state
+func (w *BootstrapWatcher) Status() BootstrapStatus {
+	nodes := w.client.GetStats().NodesInRoutingTable
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	if w.completedAt.IsZero() && nodes > 0 {
+		w.completedAt = now
+	}
+
+	status := BootstrapStatus{
+		State:       BootstrapPending,
+		Nodes:       nodes,
+		StartedAt:   w.startedAt,
+		CompletedAt: w.completedAt,
+		Timeout:     w.timeout,
+	}
+	switch {
+	case !w.completedAt.IsZero():
+		status.State = BootstrapComplete
+	case now.Sub(w.startedAt) >= w.timeout:
+		status.State = BootstrapTimedOut
+	}
+	return status
+}
@@ -0,0 +1,70 @@
+package dht
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBootstrapWatcher verifies bootstrap completes once a node is known
+func TestBootstrapWatcher(t *testing.T) {
+	client := newMockDHTClient()
+	watcher := NewBootstrapWatcher(client, time.Minute)
+
+	now := watcher.startedAt
+	watcher.now = func() time.Time { return now }
+
+	if status := watcher.Status(); status.State != BootstrapPending {
+		t.Errorf("Expected pending before any node is known, got %s", status.State)
+	}
+
+	now = now.Add(10 * time.Second)
+	client.mu.Lock()
+	client.stats.NodesInRoutingTable = 3
+	client.mu.Unlock()
+
+	status := watcher.Status()
+	if status.State != BootstrapComplete {
+		t.Fatalf("Expected complete once nodes are known, got %s", status.State)
+	}
+	if status.Nodes != 3 || !status.CompletedAt.Equal(now) {
+		t.Errorf("Unexpected status: nodes=%d completedAt=%v", status.Nodes, status.CompletedAt)
+	}
+
+	// Completion is latched even if the routing table empties again
+	client.mu.Lock()
+	client.stats.NodesInRoutingTable = 0
+	client.mu.Unlock()
+	now = now.Add(time.Hour)
+
+	if status := watcher.Status(); status.State != BootstrapComplete {
+		t.Errorf("Expected bootstrap to stay complete, got %s", status.State)
+	}
+}
+
+// TestBootstrapWatcherTimeout verifies the timed-out state and late recovery
+func TestBootstrapWatcherTimeout(t *testing.T) {
+	client := newMockDHTClient()
+	watcher := NewBootstrapWatcher(client, time.Minute)
+
+	now := watcher.startedAt
+	watcher.now = func() time.Time { return now }
+
+	now = now.Add(59 * time.Second)
+	if status := watcher.Status(); status.State != BootstrapPending {
+		t.Errorf("Expected pending before the timeout, got %s", status.State)
+	}
+
+	now = now.Add(time.Second)
+	if status := watcher.Status(); status.State != BootstrapTimedOut {
+		t.Errorf("Expected timed-out at the timeout, got %s", status.State)
+	}
+
+	// Nodes found after the timeout still complete the bootstrap
+	client.mu.Lock()
+	client.stats.NodesInRoutingTable = 1
+	client.mu.Unlock()
+
+	if status := watcher.Status(); status.State != BootstrapComplete {
+		t.Errorf("Expected late bootstrap to complete, got %s", status.State)
+	}
+}
@@ -46,6 +46,10 @@ type ClientConfig struct {
 	// Port to listen on for DHT traffic
 	Port int
 
+	// AnnouncePort is the port advertised to peers in announces
+	// (optional, defaults to Port). Useful behind NAT/port-forwarding.
+	AnnouncePort int
+
 	// Bootstrap nodes to initially connect to
 	BootstrapNodes []string
 
@@ -267,6 +271,15 @@ func (c *Client) NodeID() [20]byte {
 	return c.nodeID
 }
 
+// AnnouncePort returns the port advertised to peers in announces.
+// Falls back to the listen port when no override is configured.
+func (c *Client) AnnouncePort() int {
+	if c.config.AnnouncePort != 0 {
+		return c.config.AnnouncePort
+	}
+	return c.config.Port
+}
+
 // IsStarted returns whether the client is running
 func (c *Client) IsStarted() bool {
 	c.mu.RLock()
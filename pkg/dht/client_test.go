@@ -0,0 +1,22 @@
+package dht
+
+import "testing"
+
+// TestClientAnnouncePort verifies the announce port falls back to the listen port
+func TestClientAnnouncePort(t *testing.T) {
+	client, err := NewClient(&ClientConfig{Port: 6881})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if got := client.AnnouncePort(); got != 6881 {
+		t.Errorf("AnnouncePort without override: got %d, want 6881", got)
+	}
+
+	client, err = NewClient(&ClientConfig{Port: 6881, AnnouncePort: 51413})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if got := client.AnnouncePort(); got != 51413 {
+		t.Errorf("AnnouncePort with override: got %d, want 51413", got)
+	}
+}
@@ -17,6 +17,9 @@ type DiscoveryResult struct {
 	Peers        []net.Addr
 	DiscoveredAt time.Time
 	QueryCount   int
+	// Stale is set when the entry has outlived the cache TTL but is still
+	// within the grace period. Stale results are served but should be refreshed.
+	Stale bool
 }
 
 // Discovery manages package discovery through the DHT
@@ -25,6 +28,7 @@ type Discovery struct {
 	mu        sync.RWMutex
 	cache     map[metainfo.Hash]*DiscoveryResult
 	cacheTTL  time.Duration
+	grace     time.Duration
 	statsLock sync.RWMutex
 	stats     DiscoveryStats
 }
@@ -49,10 +53,19 @@ func NewDiscovery(client *Client, cacheTTL time.Duration) *Discovery {
 	}
 }
 
+// SetGracePeriod sets how long entries past the cache TTL are still served
+// (flagged as stale) before being dropped. Zero disables the grace window.
+func (d *Discovery) SetGracePeriod(grace time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.grace = grace
+}
+
 // FindPeers finds peers for a package by its info hash
 func (d *Discovery) FindPeers(ctx context.Context, infoHash metainfo.Hash, packageName string) ([]net.Addr, error) {
 	// Check cache first
-	if result := d.checkCache(infoHash); result != nil {
+	result := d.checkCache(infoHash)
+	if result != nil && !result.Stale {
 		d.updateStats(true, len(result.Peers), nil)
 		return result.Peers, nil
 	}
@@ -60,6 +73,11 @@ func (d *Discovery) FindPeers(ctx context.Context, infoHash metainfo.Hash, packa
 	// Query DHT
 	peers, err := d.client.GetPeers(infoHash)
 	if err != nil {
+		// Fall back to a stale entry rather than failing during DHT churn
+		if result != nil {
+			d.updateStats(true, len(result.Peers), nil)
+			return result.Peers, nil
+		}
 		d.updateStats(false, 0, err)
 		return nil, err
 	}
@@ -71,7 +89,9 @@ func (d *Discovery) FindPeers(ctx context.Context, infoHash metainfo.Hash, packa
 	return peers, nil
 }
 
-// checkCache checks if a result is in cache and still valid
+// checkCache checks if a result is in cache and still servable.
+// Entries past the TTL but within the grace period are returned as a copy
+// with Stale set; entries beyond the grace period are treated as a miss.
 func (d *Discovery) checkCache(infoHash metainfo.Hash) *DiscoveryResult {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -81,12 +101,30 @@ func (d *Discovery) checkCache(infoHash metainfo.Hash) *DiscoveryResult {
 		return nil
 	}
 
-	// Check if cache entry is still valid
-	if time.Since(result.DiscoveredAt) > d.cacheTTL {
+	stale, servable := d.freshness(result, time.Now())
+	if !servable {
 		return nil
 	}
 
-	return result
+	resultCopy := *result
+	resultCopy.Peers = make([]net.Addr, len(result.Peers))
+	copy(resultCopy.Peers, result.Peers)
+	resultCopy.Stale = stale
+
+	return &resultCopy
+}
+
+// freshness reports whether a cache entry is stale and whether it can still
+// be served at the given time (must be called with lock held)
+func (d *Discovery) freshness(result *DiscoveryResult, now time.Time) (stale bool, servable bool) {
+	age := now.Sub(result.DiscoveredAt)
+	if age <= d.cacheTTL {
+		return false, true
+	}
+	if age <= d.cacheTTL+d.grace {
+		return true, true
+	}
+	return false, false
 }
 
 // updateCache updates the cache with new discovery results
@@ -111,19 +149,15 @@ func (d *Discovery) updateCache(infoHash metainfo.Hash, packageName string, peer
 	}
 }
 
-// GetCachedResult returns a cached result if available
+// GetCachedResult returns a cached result if available.
+// Results served from the grace window have Stale set.
 func (d *Discovery) GetCachedResult(infoHash metainfo.Hash) (*DiscoveryResult, bool) {
 	result := d.checkCache(infoHash)
 	if result == nil {
 		return nil, false
 	}
 
-	// Return a copy to avoid race conditions
-	resultCopy := *result
-	resultCopy.Peers = make([]net.Addr, len(result.Peers))
-	copy(resultCopy.Peers, result.Peers)
-
-	return &resultCopy, true
+	return result, true
 }
 
 // GetAllResults returns all cached results
@@ -135,11 +169,13 @@ func (d *Discovery) GetAllResults() []*DiscoveryResult {
 	now := time.Now()
 
 	for _, result := range d.cache {
-		// Only return valid cache entries
-		if now.Sub(result.DiscoveredAt) <= d.cacheTTL {
+		// Only return servable cache entries (stale ones are flagged)
+		stale, servable := d.freshness(result, now)
+		if servable {
 			resultCopy := *result
 			resultCopy.Peers = make([]net.Addr, len(result.Peers))
 			copy(resultCopy.Peers, result.Peers)
+			resultCopy.Stale = stale
 			results = append(results, &resultCopy)
 		}
 	}
@@ -155,7 +191,7 @@ func (d *Discovery) ClearCache() {
 	d.cache = make(map[metainfo.Hash]*DiscoveryResult)
 }
 
-// ClearExpired removes expired entries from the cache
+// ClearExpired removes entries that are past both the TTL and the grace period
 func (d *Discovery) ClearExpired() int {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -164,7 +200,7 @@ func (d *Discovery) ClearExpired() int {
 	removed := 0
 
 	for hash, result := range d.cache {
-		if now.Sub(result.DiscoveredAt) > d.cacheTTL {
+		if _, servable := d.freshness(result, now); !servable {
 			delete(d.cache, hash)
 			removed++
 		}
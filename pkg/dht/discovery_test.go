@@ -0,0 +1,89 @@
+package dht
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// seedCache inserts a discovery result of the given age directly into the cache
+func seedCache(d *Discovery, suffix byte, age time.Duration) {
+	infoHash := testInfoHash(suffix)
+	d.cache[infoHash] = &DiscoveryResult{
+		InfoHash:     infoHash,
+		PackageName:  "test-pkg",
+		Peers:        []net.Addr{&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 6881}},
+		DiscoveredAt: time.Now().Add(-age),
+		QueryCount:   1,
+	}
+}
+
+// TestDiscoveryCacheFresh verifies entries within the TTL are served and not stale
+func TestDiscoveryCacheFresh(t *testing.T) {
+	d := NewDiscovery(nil, time.Minute)
+	d.SetGracePeriod(time.Minute)
+	seedCache(d, 1, 30*time.Second)
+
+	result, ok := d.GetCachedResult(testInfoHash(1))
+	if !ok {
+		t.Fatal("Fresh entry should be served")
+	}
+	if result.Stale {
+		t.Error("Fresh entry should not be flagged stale")
+	}
+	if len(result.Peers) != 1 {
+		t.Errorf("Peers: got %d, want 1", len(result.Peers))
+	}
+}
+
+// TestDiscoveryCacheWithinGrace verifies expired entries within the grace window are served as stale
+func TestDiscoveryCacheWithinGrace(t *testing.T) {
+	d := NewDiscovery(nil, time.Minute)
+	d.SetGracePeriod(time.Minute)
+	seedCache(d, 1, 90*time.Second)
+
+	result, ok := d.GetCachedResult(testInfoHash(1))
+	if !ok {
+		t.Fatal("Entry within grace should be served")
+	}
+	if !result.Stale {
+		t.Error("Entry within grace should be flagged stale")
+	}
+
+	// The flag is per-lookup and must not leak into the cached entry
+	if d.cache[testInfoHash(1)].Stale {
+		t.Error("Cached entry should not be mutated")
+	}
+
+	// Within-grace entries survive expiry cleanup
+	if removed := d.ClearExpired(); removed != 0 {
+		t.Errorf("ClearExpired removed %d entries, want 0", removed)
+	}
+}
+
+// TestDiscoveryCacheBeyondGrace verifies entries past the grace window are a miss
+func TestDiscoveryCacheBeyondGrace(t *testing.T) {
+	d := NewDiscovery(nil, time.Minute)
+	d.SetGracePeriod(time.Minute)
+	seedCache(d, 1, 3*time.Minute)
+
+	if _, ok := d.GetCachedResult(testInfoHash(1)); ok {
+		t.Error("Entry beyond grace should be a miss")
+	}
+	if results := d.GetAllResults(); len(results) != 0 {
+		t.Errorf("GetAllResults: got %d results, want 0", len(results))
+	}
+	if removed := d.ClearExpired(); removed != 1 {
+		t.Errorf("ClearExpired removed %d entries, want 1", removed)
+	}
+}
+
+// TestDiscoveryCacheNoGrace verifies the default behavior drops entries at the TTL
+func TestDiscoveryCacheNoGrace(t *testing.T) {
+	d := NewDiscovery(nil, time.Minute)
+	seedCache(d, 1, 90*time.Second)
+
+	if _, ok := d.GetCachedResult(testInfoHash(1)); ok {
+		t.Error("Expired entry should be a miss without a grace period")
+	}
+}
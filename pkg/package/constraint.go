@@ -0,0 +1,169 @@
+package packagetypes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a parsed version range such as ">=1.0.0 <2.0.0" or "^2.1.0".
+//
+// Supported syntax:
+//   - exact versions ("1.2.3" or "=1.2.3")
+//   - comparisons (">1.2.3", ">=1.2.3", "<1.2.3", "<=1.2.3")
+//   - caret ranges ("^1.2.3" allows changes that keep the leftmost non-zero component)
+//   - tilde ranges ("~1.2.3" allows patch-level changes)
+//   - "*" matching any version
+//
+// Comparators separated by spaces or commas must all match; alternatives are
+// separated by "||". Whitespace between an operator and its version is
+// allowed (">= 1.0.0").
+//
+// As in npm, a prerelease version only matches if a comparator in the same
+// range has the same major.minor.patch and a prerelease, so "^1.0.0" does
+// not match "2.0.0-beta.1" but ">=1.0.0-beta.1" matches "1.0.0-beta.2".
+type Constraint struct {
+	raw    string
+	groups [][]comparator
+}
+
+// comparator is a single operator/version pair
+type comparator struct {
+	op      string
+	version SemVer
+}
+
+// ParseConstraint parses a version range.
+//
+// Returns error if the range is empty or contains an invalid comparator.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+
+	for _, alt := range strings.Split(s, "||") {
+		fields := strings.FieldsFunc(alt, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		})
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid version constraint %q: empty range", s)
+		}
+
+		var group []comparator
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// Join an operator written apart from its version
+			if isRangeOperator(field) && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+			comparators, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			group = append(group, comparators...)
+		}
+		c.groups = append(c.groups, group)
+	}
+
+	return c, nil
+}
+
+// rangeOperators lists the recognized operators, longest first.
+var rangeOperators = []string{">=", "<=", ">", "<", "=", "^", "~"}
+
+// isRangeOperator reports whether a term is a bare operator with no version.
+func isRangeOperator(term string) bool {
+	for _, op := range rangeOperators {
+		if term == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparator expands one range term into plain comparators.
+func parseComparator(term string) ([]comparator, error) {
+	if term == "*" {
+		return nil, nil
+	}
+
+	op := ""
+	for _, candidate := range rangeOperators {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	v, err := ParseSemVer(strings.TrimPrefix(term, op))
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=":
+		return []comparator{{"=", v}}, nil
+	case "^":
+		upper := SemVer{Major: v.Major + 1}
+		switch {
+		case v.Major == 0 && v.Minor == 0:
+			upper = SemVer{Patch: v.Patch + 1}
+		case v.Major == 0:
+			upper = SemVer{Minor: v.Minor + 1}
+		}
+		return []comparator{{">=", v}, {"<", upper}}, nil
+	case "~":
+		return []comparator{{">=", v}, {"<", SemVer{Major: v.Major, Minor: v.Minor + 1}}}, nil
+	}
+	return []comparator{{op, v}}, nil
+}
+
+// Matches reports whether the version satisfies the constraint.
+func (c *Constraint) Matches(v SemVer) bool {
+	for _, group := range c.groups {
+		matched := true
+		for _, cmp := range group {
+			if !cmp.matches(v) {
+				matched = false
+				break
+			}
+		}
+		if matched && (len(v.Prerelease) == 0 || allowsPrerelease(group, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraint as originally written.
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// allowsPrerelease reports whether a comparator in the group opts in to
+// prereleases of v's major.minor.patch.
+func allowsPrerelease(group []comparator, v SemVer) bool {
+	for _, cmp := range group {
+		if len(cmp.version.Prerelease) > 0 &&
+			cmp.version.Major == v.Major && cmp.version.Minor == v.Minor && cmp.version.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// matches applies a single comparator
+func (cmp comparator) matches(v SemVer) bool {
+	result := v.Compare(cmp.version)
+	switch cmp.op {
+	case "=":
+		return result == 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return false
+}
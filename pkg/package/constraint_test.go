@@ -0,0 +1,123 @@
+package packagetypes
+
+import "testing"
+
+// TestConstraintMatches tests version range matching.
+func TestConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{">=1.0.0", "1.0.0", true},
+		{">=1.0.0", "0.9.9", false},
+		{">1.0.0", "1.0.0", false},
+		{"<2.0.0", "1.99.0", true},
+		{"<=2.0.0", "2.0.0", true},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0, <2.0.0", "2.0.0", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"*", "0.0.1", true},
+		{"<1.0.0 || >=2.0.0", "2.1.0", true},
+		{"<1.0.0 || >=2.0.0", "1.5.0", false},
+		{">= 1.0.0", "1.5.0", true},
+		{">= 1.0.0, < 2.0.0", "2.0.0", false},
+		{"^ 1.2.3", "1.9.0", true},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+		}
+		v, err := ParseSemVer(tt.version)
+		if err != nil {
+			t.Fatalf("ParseSemVer(%q) failed: %v", tt.version, err)
+		}
+		if got := c.Matches(v); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+// TestConstraintMatches_Prerelease tests that prereleases only match ranges
+// that opt in to the same major.minor.patch.
+func TestConstraintMatches_Prerelease(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"^1.0.0", "2.0.0-beta.1", false},
+		{"^1.0.0", "1.5.0-rc.1", false},
+		{"<2.0.0", "2.0.0-beta.1", false},
+		{"*", "1.0.0-alpha", false},
+		{">=1.0.0-beta.1", "1.0.0-beta.2", true},
+		{">=1.0.0-beta.1", "1.0.0-alpha", false},
+		{">=1.0.0-beta.1", "1.0.1-beta", false},
+		{"^1.0.0-beta.1", "1.0.0-rc.1", true},
+		{"^1.0.0-beta.1", "1.2.0", true},
+		{"1.0.0-beta.1", "1.0.0-beta.1", true},
+		{"<1.0.0 || >=2.0.0-rc.1", "2.0.0-rc.2", true},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+		}
+		v, err := ParseSemVer(tt.version)
+		if err != nil {
+			t.Fatalf("ParseSemVer(%q) failed: %v", tt.version, err)
+		}
+		if got := c.Matches(v); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+// TestParseConstraint_Invalid tests that malformed ranges are rejected.
+func TestParseConstraint_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"   ",
+		">=",
+		">= ",
+		"1.0.0 >=",
+		">=1.0",
+		"^latest",
+		">=1.0.0 ||",
+		"=>1.0.0",
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+// TestDependencyValidate_UnparsedRange tests that manifests accept version
+// ranges the constraint parser does not understand.
+func TestDependencyValidate_UnparsedRange(t *testing.T) {
+	for _, constraint := range []string{"^1.0.0", ">= 1.0.0", "1.x", ">=one"} {
+		dep := Dependency{PackageName: "lib", VersionConstraint: constraint}
+		if err := dep.Validate(); err != nil {
+			t.Errorf("expected %q to be a valid dependency, got %v", constraint, err)
+		}
+	}
+
+	empty := Dependency{PackageName: "lib"}
+	if err := empty.Validate(); err == nil {
+		t.Error("expected error for missing version constraint")
+	}
+}
@@ -66,6 +66,9 @@ func (d *MinimalDescription) Validate() error {
 	if d.Name == "" {
 		return fmt.Errorf("minimal description: name is required")
 	}
+	if err := ValidateNameChars(d.Name); err != nil {
+		return fmt.Errorf("minimal description: name: %w", err)
+	}
 	if d.Version == "" {
 		return fmt.Errorf("minimal description: version is required")
 	}
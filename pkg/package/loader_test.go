@@ -321,3 +321,36 @@ func createTestPackageFile(t *testing.T) (string, *Package) {
 
 	return tmpFile, pkg
 }
+
+// TestSerializePackage_PlatformArch tests that target platform fields survive
+// serialization and are omitted when unset.
+func TestSerializePackage_PlatformArch(t *testing.T) {
+	pkg := createTestPackage(t)
+
+	manifestData, err := SerializeManifest(&pkg.Manifest)
+	if err != nil {
+		t.Fatalf("SerializeManifest failed: %v", err)
+	}
+	if strings.Contains(string(manifestData), "platform:") || strings.Contains(string(manifestData), "arch:") {
+		t.Errorf("unset platform/arch should be omitted from the manifest:\n%s", manifestData)
+	}
+
+	pkg.Manifest.Platform = "linux"
+	pkg.Manifest.Arch = "amd64"
+
+	data, err := SerializePackage(pkg)
+	if err != nil {
+		t.Fatalf("SerializePackage failed: %v", err)
+	}
+
+	loadedPkg, err := LoadPackageFromBytes(data)
+	if err != nil {
+		t.Fatalf("LoadPackageFromBytes failed: %v", err)
+	}
+	if loadedPkg.Manifest.Platform != "linux" {
+		t.Errorf("Platform mismatch: got %q, want %q", loadedPkg.Manifest.Platform, "linux")
+	}
+	if loadedPkg.Manifest.Arch != "amd64" {
+		t.Errorf("Arch mismatch: got %q, want %q", loadedPkg.Manifest.Arch, "amd64")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/libreseed/libreseed/pkg/crypto"
@@ -38,6 +39,9 @@ type ConfigSchema struct {
 }
 
 // Validate checks that the Dependency contains valid data.
+// The version constraint is not parsed here, so packages declaring ranges
+// this version does not understand can still be loaded; they are reported
+// as invalid when dependencies are resolved.
 func (d *Dependency) Validate() error {
 	if d.PackageName == "" {
 		return fmt.Errorf("dependency: package_name is required")
@@ -45,7 +49,6 @@ func (d *Dependency) Validate() error {
 	if d.VersionConstraint == "" {
 		return fmt.Errorf("dependency: version_constraint is required")
 	}
-	// TODO: Add semantic version constraint validation
 	return nil
 }
 
@@ -90,6 +93,14 @@ type Manifest struct {
 	// Both creator and maintainer signatures are required for package trust
 	MaintainerPubKey crypto.PublicKey `yaml:"maintainer_pubkey" json:"maintainer_pubkey"`
 
+	// Platform is the target operating system (e.g., "linux", "darwin")
+	// Empty means the package is platform-independent
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
+	// Arch is the target CPU architecture (e.g., "amd64", "arm64")
+	// Empty means the package is architecture-independent
+	Arch string `yaml:"arch,omitempty" json:"arch,omitempty"`
+
 	// Dependencies lists all packages required by this package
 	// The package manager must resolve and approve dependencies before installation
 	Dependencies []Dependency `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
@@ -100,6 +111,7 @@ type Manifest struct {
 
 	// ContentHash is the SHA-256 hash of all package content files
 	// This ensures tamper-proof content integrity
+	// See ComputeManifestContentHash for the canonical computation
 	ContentHash string `yaml:"content_hash" json:"content_hash"`
 
 	// ContentList describes all files included in the package
@@ -163,6 +175,9 @@ func (m *Manifest) Validate() error {
 	if m.PackageName == "" {
 		return fmt.Errorf("manifest: package_name is required")
 	}
+	if err := ValidateNameChars(m.PackageName); err != nil {
+		return fmt.Errorf("manifest: package_name: %w", err)
+	}
 	if m.Version == "" {
 		return fmt.Errorf("manifest: version is required")
 	}
@@ -179,6 +194,13 @@ func (m *Manifest) Validate() error {
 		return fmt.Errorf("manifest: content_hash is required")
 	}
 
+	if err := validateTargetToken(m.Platform); err != nil {
+		return fmt.Errorf("manifest: platform: %w", err)
+	}
+	if err := validateTargetToken(m.Arch); err != nil {
+		return fmt.Errorf("manifest: arch: %w", err)
+	}
+
 	// Validate dependencies
 	for i, dep := range m.Dependencies {
 		if err := dep.Validate(); err != nil {
@@ -207,16 +229,32 @@ func (m *Manifest) Validate() error {
 		return fmt.Errorf("manifest: content_hash must be valid hex: %w", err)
 	}
 
-	// Validate each file entry
+	// Validate each file entry; paths must be unique
+	seen := make(map[string]bool, len(m.ContentList))
 	for i, entry := range m.ContentList {
 		if err := entry.Validate(); err != nil {
 			return fmt.Errorf("manifest: content_list[%d]: %w", i, err)
 		}
+		if seen[entry.Path] {
+			return fmt.Errorf("manifest: content_list[%d]: duplicate path %q", i, entry.Path)
+		}
+		seen[entry.Path] = true
 	}
 
 	return nil
 }
 
+// validateTargetToken checks an optional platform or arch identifier.
+// Identifiers follow Go's GOOS/GOARCH style: lowercase letters, digits and underscores.
+func validateTargetToken(token string) error {
+	for _, r := range token {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return fmt.Errorf("%q must contain only lowercase letters, digits and underscores", token)
+		}
+	}
+	return nil
+}
+
 // Validate checks that the FileEntry contains valid data.
 func (f *FileEntry) Validate() error {
 	if f.Path == "" {
@@ -237,6 +275,45 @@ func (f *FileEntry) Validate() error {
 	return nil
 }
 
+// ComputeManifestContentHash computes the canonical content hash of a file list.
+// Entries are sorted by path before hashing, so the result does not depend on
+// the order of ContentList. Each entry contributes its path, hash, size and mode.
+// Validate rejects duplicate paths; should any reach here, they are ordered by
+// the remaining fields so the hash stays independent of input order.
+func ComputeManifestContentHash(files []FileEntry) string {
+	sorted := make([]FileEntry, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Hash != b.Hash {
+			return a.Hash < b.Hash
+		}
+		if a.Size != b.Size {
+			return a.Size < b.Size
+		}
+		return a.Mode < b.Mode
+	})
+
+	h := sha256.New()
+	for _, f := range sorted {
+		// NUL separators keep field boundaries unambiguous
+		fmt.Fprintf(h, "%s\x00%s\x00%d\x00%o\n", f.Path, f.Hash, f.Size, f.Mode)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyContentHash checks that ContentHash matches the canonical hash of ContentList.
+func (m *Manifest) VerifyContentHash() error {
+	computed := ComputeManifestContentHash(m.ContentList)
+	if m.ContentHash != computed {
+		return fmt.Errorf("manifest: content_hash mismatch: declared %s, computed %s", m.ContentHash, computed)
+	}
+	return nil
+}
+
 // ComputePackageID computes the SHA-256 hash of the complete .lspkg file.
 // This provides a unique, content-addressed identifier for the package.
 func (p *Package) ComputePackageID(fileContent []byte) string {
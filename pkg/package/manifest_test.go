@@ -0,0 +1,195 @@
+package packagetypes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libreseed/libreseed/pkg/crypto"
+)
+
+// testContentList returns a small content list for content hash tests.
+func testContentList() []FileEntry {
+	return []FileEntry{
+		{Path: "README.md", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Size: 0, Mode: 0644},
+		{Path: "bin/tool", Hash: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", Size: 5, Mode: 0755},
+		{Path: "src/main.go", Hash: "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7", Size: 11, Mode: 0644},
+	}
+}
+
+// TestComputeManifestContentHash_OrderIndependent tests that shuffling ContentList keeps the hash.
+func TestComputeManifestContentHash_OrderIndependent(t *testing.T) {
+	files := testContentList()
+	expected := ComputeManifestContentHash(files)
+
+	shuffled := []FileEntry{files[2], files[0], files[1]}
+	if got := ComputeManifestContentHash(shuffled); got != expected {
+		t.Errorf("hash changed after reordering: got %s, want %s", got, expected)
+	}
+
+	// Input must not be reordered in place
+	if shuffled[0].Path != "src/main.go" {
+		t.Error("ComputeManifestContentHash modified its input")
+	}
+
+	if len(expected) != 64 {
+		t.Errorf("expected 64-character hex hash, got %d characters", len(expected))
+	}
+}
+
+// TestComputeManifestContentHash_DetectsChanges tests that any field change alters the hash.
+func TestComputeManifestContentHash_DetectsChanges(t *testing.T) {
+	original := ComputeManifestContentHash(testContentList())
+
+	mutations := map[string]func(*FileEntry){
+		"path": func(f *FileEntry) { f.Path = "README.txt" },
+		"hash": func(f *FileEntry) { f.Hash = "0000000000000000000000000000000000000000000000000000000000000000" },
+		"size": func(f *FileEntry) { f.Size = 1 },
+		"mode": func(f *FileEntry) { f.Mode = 0600 },
+	}
+
+	for field, mutate := range mutations {
+		files := testContentList()
+		mutate(&files[0])
+		if ComputeManifestContentHash(files) == original {
+			t.Errorf("changing %s did not change the content hash", field)
+		}
+	}
+
+	files := testContentList()
+	if ComputeManifestContentHash(files[:2]) == original {
+		t.Error("removing a file did not change the content hash")
+	}
+}
+
+// TestManifestVerifyContentHash tests verification of the declared content hash.
+func TestManifestVerifyContentHash(t *testing.T) {
+	m := &Manifest{ContentList: testContentList()}
+	m.ContentHash = ComputeManifestContentHash(m.ContentList)
+
+	if err := m.VerifyContentHash(); err != nil {
+		t.Errorf("expected matching content hash, got %v", err)
+	}
+
+	m.ContentList[1].Size = 6
+	if err := m.VerifyContentHash(); err == nil {
+		t.Error("expected mismatch after content list change")
+	}
+}
+
+// TestValidateNameChars tests rejection of NUL bytes and control characters in names.
+func TestValidateNameChars(t *testing.T) {
+	valid := []string{"my-library", "lib_2", "paquet-été", "名前"}
+	for _, name := range valid {
+		if err := ValidateNameChars(name); err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+	}
+
+	invalid := []string{
+		string(make([]byte, 8)),
+		"lib\x00name",
+		"lib\nname",
+		"lib\tname",
+		"lib\x7fname",
+		"lib\u0085name",
+		"lib\xffname",
+	}
+	for _, name := range invalid {
+		if err := ValidateNameChars(name); !errors.Is(err, ErrInvalidNameChars) {
+			t.Errorf("expected ErrInvalidNameChars for %q, got %v", name, err)
+		}
+	}
+}
+
+// TestNameValidation_ManifestAndDescription tests that both name fields reject control characters.
+func TestNameValidation_ManifestAndDescription(t *testing.T) {
+	m := &Manifest{PackageName: "lib\x00name"}
+	if err := m.Validate(); !errors.Is(err, ErrInvalidNameChars) {
+		t.Errorf("expected manifest to reject NUL in package_name, got %v", err)
+	}
+
+	d := &MinimalDescription{
+		PackageID:        strings.Repeat("a", 64),
+		CreatorPubKey:    crypto.PublicKey{Algorithm: "ed25519"},
+		MaintainerPubKey: crypto.PublicKey{Algorithm: "ed25519"},
+		Name:             "lib\x01name",
+		Version:          "1.0.0",
+		ShortDescription: "test",
+		DHTKey:           strings.Repeat("b", 40),
+	}
+	if err := d.Validate(); !errors.Is(err, ErrInvalidNameChars) {
+		t.Errorf("expected description to reject control character in name, got %v", err)
+	}
+
+	d.Name = "my-library"
+	if err := d.Validate(); err != nil {
+		t.Errorf("expected valid description, got %v", err)
+	}
+}
+
+// TestManifestValidate_PlatformArch tests validation of the optional target fields.
+func TestManifestValidate_PlatformArch(t *testing.T) {
+	tests := []struct {
+		platform, arch string
+		valid          bool
+	}{
+		{"", "", true},
+		{"linux", "amd64", true},
+		{"darwin", "arm64", true},
+		{"linux", "", true},
+		{"Linux", "", false},
+		{"linux", "x86-64", false},
+		{"linux/amd64", "", false},
+	}
+
+	for _, tt := range tests {
+		m := &Manifest{
+			PackageName:      "test-package",
+			Version:          "1.0.0",
+			Description:      "test",
+			CreatorPubKey:    crypto.PublicKey{Algorithm: "ed25519"},
+			MaintainerPubKey: crypto.PublicKey{Algorithm: "ed25519"},
+			ContentHash:      strings.Repeat("a", 64),
+			ContentList:      testContentList(),
+			CreatedAt:        time.Now(),
+			Platform:         tt.platform,
+			Arch:             tt.arch,
+		}
+
+		err := m.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("platform=%q arch=%q: expected valid, got %v", tt.platform, tt.arch, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("platform=%q arch=%q: expected validation error", tt.platform, tt.arch)
+		}
+	}
+}
+
+// TestManifestValidate_DuplicatePaths tests that a content list cannot name a path twice.
+func TestManifestValidate_DuplicatePaths(t *testing.T) {
+	files := testContentList()
+	files = append(files, FileEntry{Path: files[0].Path, Hash: files[1].Hash, Size: 5, Mode: 0644})
+
+	m := &Manifest{
+		PackageName:      "test-package",
+		Version:          "1.0.0",
+		Description:      "test",
+		CreatorPubKey:    crypto.PublicKey{Algorithm: "ed25519"},
+		MaintainerPubKey: crypto.PublicKey{Algorithm: "ed25519"},
+		ContentHash:      ComputeManifestContentHash(files),
+		ContentList:      files,
+		CreatedAt:        time.Now(),
+	}
+	if err := m.Validate(); err == nil || !strings.Contains(err.Error(), "duplicate path") {
+		t.Errorf("expected duplicate path error, got %v", err)
+	}
+
+	// The hash itself still does not depend on the order of duplicates
+	swapped := append(testContentList()[1:], files[3], files[0])
+	if ComputeManifestContentHash(swapped) != ComputeManifestContentHash(files) {
+		t.Error("hash depends on the order of entries sharing a path")
+	}
+}
@@ -0,0 +1,26 @@
+package packagetypes
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidNameChars is returned when a package name contains NUL bytes,
+// control characters, or invalid UTF-8.
+var ErrInvalidNameChars = errors.New("name contains invalid characters")
+
+// ValidateNameChars checks that a package name contains only printable text.
+// Length and emptiness are checked separately by the callers.
+func ValidateNameChars(name string) error {
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("%w: invalid UTF-8", ErrInvalidNameChars)
+	}
+	for i, r := range name {
+		if r == 0 || unicode.IsControl(r) {
+			return fmt.Errorf("%w: control character %U at byte %d", ErrInvalidNameChars, r, i)
+		}
+	}
+	return nil
+}
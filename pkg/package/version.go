@@ -0,0 +1,210 @@
+package packagetypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a parsed semantic version (MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]).
+type SemVer struct {
+	// Major, Minor and Patch are the numeric version components
+	Major uint64
+	Minor uint64
+	Patch uint64
+
+	// Prerelease holds the dot-separated prerelease identifiers (e.g., ["beta", "1"])
+	Prerelease []string
+
+	// Build holds the dot-separated build metadata identifiers; ignored for ordering
+	Build []string
+}
+
+// ParseSemVer parses a semantic version string.
+// Numeric components must not have leading zeros and identifiers must be
+// non-empty and contain only ASCII alphanumerics and hyphens.
+//
+// Returns error if the string is not a valid semantic version.
+func ParseSemVer(v string) (SemVer, error) {
+	var sv SemVer
+	rest := v
+
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		build, err := parseIdentifiers(rest[i+1:], false)
+		if err != nil {
+			return SemVer{}, fmt.Errorf("invalid version %q: build: %w", v, err)
+		}
+		sv.Build = build
+		rest = rest[:i]
+	}
+
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		pre, err := parseIdentifiers(rest[i+1:], true)
+		if err != nil {
+			return SemVer{}, fmt.Errorf("invalid version %q: prerelease: %w", v, err)
+		}
+		sv.Prerelease = pre
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return SemVer{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", v)
+	}
+
+	core := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := parseNumeric(part)
+		if err != nil {
+			return SemVer{}, fmt.Errorf("invalid version %q: %w", v, err)
+		}
+		core[i] = n
+	}
+	sv.Major, sv.Minor, sv.Patch = core[0], core[1], core[2]
+
+	return sv, nil
+}
+
+// String formats the version; ParseSemVer(v.String()) returns an equal SemVer.
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare orders two versions by semver precedence; build metadata is ignored.
+//
+// Returns -1 if v < other, 0 if they have equal precedence, and 1 if v > other.
+func (v SemVer) Compare(other SemVer) int {
+	for _, pair := range [][2]uint64{
+		{v.Major, other.Major},
+		{v.Minor, other.Minor},
+		{v.Patch, other.Patch},
+	} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// Less reports whether v has lower precedence than other.
+func (v SemVer) Less(other SemVer) bool {
+	return v.Compare(other) < 0
+}
+
+// CompareVersions compares two semantic version strings.
+// Build metadata is ignored and a prerelease sorts before its release version.
+// Strings that are not valid semantic versions sort before valid ones and
+// are compared lexically among themselves.
+//
+// Returns -1 if a < b, 0 if a == b, and 1 if a > b.
+func CompareVersions(a, b string) int {
+	svA, errA := ParseSemVer(a)
+	svB, errB := ParseSemVer(b)
+
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+
+	return svA.Compare(svB)
+}
+
+// parseNumeric parses a numeric identifier, rejecting leading zeros.
+func parseNumeric(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty numeric component")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("numeric component %q has leading zero", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric component %q", s)
+	}
+	return n, nil
+}
+
+// parseIdentifiers splits and validates dot-separated prerelease or build identifiers.
+func parseIdentifiers(s string, prerelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("empty identifier")
+		}
+		numeric := true
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return nil, fmt.Errorf("invalid character %q in identifier %q", c, id)
+			}
+		}
+		// Numeric prerelease identifiers must not have leading zeros
+		if prerelease && numeric && len(id) > 1 && id[0] == '0' {
+			return nil, fmt.Errorf("numeric identifier %q has leading zero", id)
+		}
+	}
+	return ids, nil
+}
+
+// comparePrerelease orders prerelease identifiers following semver precedence rules.
+func comparePrerelease(a, b []string) int {
+	// A version without prerelease has higher precedence
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		numA, errA := strconv.ParseUint(a[i], 10, 64)
+		numB, errB := strconv.ParseUint(b[i], 10, 64)
+
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			// Numeric identifiers have lower precedence than alphanumeric ones
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
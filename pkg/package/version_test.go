@@ -0,0 +1,126 @@
+package packagetypes
+
+import "testing"
+
+// TestCompareVersions tests semantic version ordering.
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.2.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"not-a-version", "1.0.0", -1},
+		{"1.0", "0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+// TestParseSemVer tests parsing of valid and invalid version strings.
+func TestParseSemVer(t *testing.T) {
+	sv, err := ParseSemVer("1.2.3-beta.1+build.42")
+	if err != nil {
+		t.Fatalf("ParseSemVer failed: %v", err)
+	}
+	if sv.Major != 1 || sv.Minor != 2 || sv.Patch != 3 {
+		t.Errorf("unexpected core version: %d.%d.%d", sv.Major, sv.Minor, sv.Patch)
+	}
+	if len(sv.Prerelease) != 2 || sv.Prerelease[0] != "beta" || sv.Prerelease[1] != "1" {
+		t.Errorf("unexpected prerelease: %v", sv.Prerelease)
+	}
+	if len(sv.Build) != 2 || sv.Build[0] != "build" || sv.Build[1] != "42" {
+		t.Errorf("unexpected build: %v", sv.Build)
+	}
+
+	invalid := []string{
+		"",
+		"1.0",
+		"1.0.0.0",
+		"v1.0.0",
+		"01.0.0",
+		"1.0.0-",
+		"1.0.0-beta..1",
+		"1.0.0-01",
+		"1.0.0+",
+		"1.0.0-beta_1",
+		"a.b.c",
+	}
+	for _, v := range invalid {
+		if _, err := ParseSemVer(v); err == nil {
+			t.Errorf("expected error parsing %q", v)
+		}
+	}
+}
+
+// TestSemVerLess tests prerelease precedence as defined by semver.
+func TestSemVerLess(t *testing.T) {
+	// Each version has lower precedence than the next
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := ParseSemVer(ordered[i])
+		b, _ := ParseSemVer(ordered[i+1])
+		if !a.Less(b) {
+			t.Errorf("expected %s < %s", ordered[i], ordered[i+1])
+		}
+		if b.Less(a) {
+			t.Errorf("expected not %s < %s", ordered[i+1], ordered[i])
+		}
+	}
+
+	a, _ := ParseSemVer("1.0.0+build.1")
+	b, _ := ParseSemVer("1.0.0+build.2")
+	if a.Less(b) || b.Less(a) {
+		t.Error("build metadata must not affect precedence")
+	}
+}
+
+// TestSemVerString tests that formatting round-trips through parsing.
+func TestSemVerString(t *testing.T) {
+	versions := []string{
+		"0.0.1",
+		"1.2.3",
+		"1.0.0-alpha",
+		"1.0.0-rc.1",
+		"1.0.0+20250101",
+		"2.1.0-beta.2+exp.sha.5114f85",
+	}
+
+	for _, v := range versions {
+		sv, err := ParseSemVer(v)
+		if err != nil {
+			t.Fatalf("ParseSemVer(%q) failed: %v", v, err)
+		}
+		if got := sv.String(); got != v {
+			t.Errorf("String() = %q, want %q", got, v)
+		}
+	}
+}